@@ -0,0 +1,331 @@
+// Package config loads service configuration in layers: struct defaults,
+// then a YAML file, then environment variables, then command-line flags.
+//
+// Configuration structs are flat and describe each field with tags:
+//
+//	type Config struct {
+//		JWTSecret string        `yaml:"jwt_secret" env:"JWT_SECRET" flag:"jwt-secret" default:"dev-secret" secret:"true" usage:"token signing key"`
+//		Timeout   time.Duration `yaml:"timeout" env:"TIMEOUT" flag:"timeout" default:"15s"`
+//	}
+//
+// Supported field types are string, bool, signed and unsigned integers,
+// float64, time.Duration and []string (comma separated in env and flags).
+//
+// Fields tagged secret:"true" are masked by Print and must be changed from
+// their default; secret:"optional" masks the field but allows it to stay empty,
+// for credentials that only some deployments need.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileFlag names the flag that points at the YAML configuration file
+	FileFlag = "config"
+	// FileEnv names the environment variable that points at the YAML configuration file
+	FileEnv = "CONFIG_FILE"
+
+	redactedValue = "[REDACTED]"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load fills dst, a pointer to a configuration struct, from every layer and
+// returns the arguments left over after flag parsing, which name the command to run
+func Load(dst interface{}, name string, args []string) ([]string, error) {
+	v, err := structValue(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := collectFields(v)
+
+	for _, f := range fields {
+		if f.def == "" {
+			continue
+		}
+		if err := f.set(f.def); err != nil {
+			return nil, fmt.Errorf("default for %s: %w", f.yaml, err)
+		}
+	}
+
+	// Flags are parsed first so --config is known, but applied last
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	file := fs.String(FileFlag, os.Getenv(FileEnv), "path to a YAML configuration file")
+	raw := make(map[string]*stringValue)
+	for _, f := range fields {
+		if f.flag == "" {
+			continue
+		}
+		sv := &stringValue{}
+		raw[f.flag] = sv
+		fs.Var(sv, f.flag, f.usageText())
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *file != "" {
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, dst); err != nil {
+			return nil, fmt.Errorf("parse config file %s: %w", *file, err)
+		}
+	}
+
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+		if value, ok := os.LookupEnv(f.env); ok {
+			if err := f.set(value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", f.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	fs.Visit(func(fl *flag.Flag) {
+		sv, ok := raw[fl.Name]
+		if !ok || flagErr != nil {
+			return
+		}
+		for _, f := range fields {
+			if f.flag == fl.Name {
+				if err := f.set(sv.value); err != nil {
+					flagErr = fmt.Errorf("invalid --%s: %w", fl.Name, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	return fs.Args(), nil
+}
+
+// InsecureSecrets lists the secret fields that are empty or still hold their
+// built-in default, so services can refuse to start with them outside debug mode
+func InsecureSecrets(cfg interface{}) []string {
+	v, err := structValue(cfg)
+	if err != nil {
+		return nil
+	}
+
+	var insecure []string
+	for _, f := range collectFields(v) {
+		if !f.secret || f.optional {
+			continue
+		}
+		if value := f.String(); value == "" || value == f.def {
+			insecure = append(insecure, f.yaml)
+		}
+	}
+	return insecure
+}
+
+// Print writes the effective configuration as YAML. With redacted set, secret
+// fields and passwords embedded in URLs are masked.
+func Print(w io.Writer, cfg interface{}, redacted bool) error {
+	v, err := structValue(cfg)
+	if err != nil {
+		return err
+	}
+
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range collectFields(v) {
+		value := f.String()
+		if redacted {
+			value = redact(f, value)
+		}
+
+		val := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+		if f.value.Kind() == reflect.Slice {
+			val = &yaml.Node{Kind: yaml.SequenceNode}
+			for _, item := range f.value.Interface().([]string) {
+				val.Content = append(val.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+			}
+		}
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.yaml}, val)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func redact(f field, value string) string {
+	if value == "" {
+		return value
+	}
+	if f.secret {
+		return redactedValue
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), "xxxxx")
+			return u.String()
+		}
+	}
+	return value
+}
+
+func structValue(dst interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errors.New("config: destination must be a pointer to a struct")
+	}
+	return v.Elem(), nil
+}
+
+// field is a tagged configuration struct field
+type field struct {
+	value  reflect.Value
+	yaml   string
+	env    string
+	flag   string
+	def    string
+	usage  string
+	secret bool
+	// optional secrets are masked but may be left empty
+	optional bool
+}
+
+func collectFields(v reflect.Value) []field {
+	t := v.Type()
+	fields := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		secret := sf.Tag.Get("secret")
+
+		fields = append(fields, field{
+			value:    v.Field(i),
+			yaml:     name,
+			env:      sf.Tag.Get("env"),
+			flag:     sf.Tag.Get("flag"),
+			def:      sf.Tag.Get("default"),
+			usage:    sf.Tag.Get("usage"),
+			secret:   secret == "true" || secret == "optional",
+			optional: secret == "optional",
+		})
+	}
+	return fields
+}
+
+func (f field) usageText() string {
+	if f.usage != "" {
+		return f.usage
+	}
+	return f.yaml
+}
+
+// set parses s according to the field's type
+func (f field) set(s string) error {
+	v := f.value
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported config type %s", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config type %s", v.Type())
+	}
+	return nil
+}
+
+// String formats the field the same way set parses it
+func (f field) String() string {
+	v := f.value
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	if v.Kind() == reflect.Slice {
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// stringValue records a flag's raw value so it can be applied after the other layers
+type stringValue struct {
+	value string
+}
+
+func (s *stringValue) String() string {
+	return s.value
+}
+
+func (s *stringValue) Set(value string) error {
+	s.value = value
+	return nil
+}
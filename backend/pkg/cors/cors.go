@@ -0,0 +1,273 @@
+// Package cors implements the cross-origin policy shared by the HTTP API and
+// the websocket upgrade checks of every service.
+//
+// Allowed origins are written as scheme://host[:port]. A leading "*." in the
+// host matches any subdomain, so https://*.disco.app allows
+// https://app.disco.app and https://eu.admin.disco.app but not
+// https://disco.app itself. A lone "*" allows every origin and cannot be
+// combined with credentials.
+package cors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options configures a policy or a per-route override
+type Options struct {
+	// AllowedOrigins lists origin patterns; empty allows no cross-origin requests
+	AllowedOrigins []string
+	// AllowedMethods are returned to preflight requests
+	AllowedMethods []string
+	// AllowedHeaders are returned to preflight requests
+	AllowedHeaders []string
+	// ExposedHeaders are readable by scripts on the allowed origin
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and Authorization headers
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// Policy decides which origins may call a service. The zero value is not
+// usable; build one with New.
+type Policy struct {
+	origins   []originPattern
+	anyOrigin bool
+
+	methods     string
+	headers     string
+	exposed     string
+	credentials bool
+	maxAge      string
+
+	// routes holds per-path-prefix overrides, longest prefix first
+	routes []route
+}
+
+type route struct {
+	prefix string
+	policy *Policy
+}
+
+// originPattern is a parsed allowed origin
+type originPattern struct {
+	scheme string
+	// host includes the port; for wildcards it is the suffix after "*"
+	host     string
+	wildcard bool
+}
+
+// ErrWildcardCredentials is returned when "*" is combined with credentials,
+// which browsers reject
+var ErrWildcardCredentials = errors.New(`cors: origin "*" cannot be used with credentials`)
+
+// New validates the options and builds a policy
+func New(opts Options) (*Policy, error) {
+	p := &Policy{
+		methods:     strings.Join(opts.AllowedMethods, ", "),
+		headers:     strings.Join(opts.AllowedHeaders, ", "),
+		exposed:     strings.Join(opts.ExposedHeaders, ", "),
+		credentials: opts.AllowCredentials,
+	}
+	if opts.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(opts.MaxAge / time.Second))
+	}
+
+	if err := p.setOrigins(opts.AllowedOrigins); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Override returns a copy of the policy that uses origins for requests whose
+// path starts with prefix. The longest matching prefix wins.
+func (p *Policy) Override(prefix string, origins []string) (*Policy, error) {
+	if !strings.HasPrefix(prefix, "/") {
+		return nil, fmt.Errorf("cors: route prefix %q must start with /", prefix)
+	}
+
+	child := *p
+	child.routes = nil
+	if err := child.setOrigins(origins); err != nil {
+		return nil, err
+	}
+
+	next := *p
+	next.routes = append(append([]route(nil), p.routes...), route{prefix: prefix, policy: &child})
+	sort.SliceStable(next.routes, func(i, j int) bool {
+		return len(next.routes[i].prefix) > len(next.routes[j].prefix)
+	})
+	return &next, nil
+}
+
+// ParseRoutes applies overrides written as "PREFIX=ORIGIN ORIGIN ...", the
+// form used in configuration files and environment variables
+func (p *Policy) ParseRoutes(specs []string) (*Policy, error) {
+	out := p
+	for _, spec := range specs {
+		prefix, origins, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("cors: route override %q must look like /prefix=origin", spec)
+		}
+		var err error
+		out, err = out.Override(strings.TrimSpace(prefix), strings.Fields(origins))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// forPath picks the override matching path, or the policy itself
+func (p *Policy) forPath(path string) *Policy {
+	for _, r := range p.routes {
+		if strings.HasPrefix(path, r.prefix) {
+			return r.policy
+		}
+	}
+	return p
+}
+
+// Allowed reports whether origin may make cross-origin requests to path
+func (p *Policy) Allowed(path, origin string) bool {
+	return p.forPath(path).allows(origin)
+}
+
+func (p *Policy) allows(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+	for _, pattern := range p.origins {
+		if pattern.scheme != u.Scheme {
+			continue
+		}
+		if pattern.wildcard {
+			if len(u.Host) > len(pattern.host) && strings.HasSuffix(u.Host, pattern.host) {
+				return true
+			}
+			continue
+		}
+		if pattern.host == u.Host {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle writes the CORS response headers for r. It reports true when r was a
+// preflight request and has been answered, so the caller must not continue.
+func (p *Policy) Handle(w http.ResponseWriter, r *http.Request) bool {
+	rp := p.forPath(r.URL.Path)
+	origin := r.Header.Get("Origin")
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+	h := w.Header()
+	h.Add("Vary", "Origin")
+	if preflight {
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	if origin == "" {
+		return false
+	}
+	if !rp.allows(origin) {
+		if preflight {
+			w.WriteHeader(http.StatusForbidden)
+			return true
+		}
+		return false
+	}
+
+	if rp.anyOrigin {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+	if rp.credentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !preflight {
+		if rp.exposed != "" {
+			h.Set("Access-Control-Expose-Headers", rp.exposed)
+		}
+		return false
+	}
+
+	if rp.methods != "" {
+		h.Set("Access-Control-Allow-Methods", rp.methods)
+	}
+	if rp.headers != "" {
+		h.Set("Access-Control-Allow-Headers", rp.headers)
+	}
+	if rp.maxAge != "" {
+		h.Set("Access-Control-Max-Age", rp.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// CheckOrigin is a websocket.Upgrader CheckOrigin func. Requests without an
+// Origin header come from native clients rather than browsers and are allowed.
+func (p *Policy) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	return p.Allowed(r.URL.Path, origin)
+}
+
+// setOrigins replaces the allowed origins with the parsed list
+func (p *Policy) setOrigins(origins []string) error {
+	p.origins = nil
+	p.anyOrigin = false
+	for _, raw := range origins {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if raw == "*" {
+			p.anyOrigin = true
+			continue
+		}
+		pattern, err := parsePattern(raw)
+		if err != nil {
+			return err
+		}
+		p.origins = append(p.origins, pattern)
+	}
+	if p.anyOrigin && p.credentials {
+		return ErrWildcardCredentials
+	}
+	return nil
+}
+
+func parsePattern(raw string) (originPattern, error) {
+	scheme, host, ok := strings.Cut(strings.ToLower(strings.TrimSuffix(raw, "/")), "://")
+	if !ok || scheme == "" || host == "" || strings.ContainsAny(host, "/?#") {
+		return originPattern{}, fmt.Errorf("cors: invalid origin %q, want scheme://host[:port]", raw)
+	}
+	if strings.HasPrefix(host, "*.") {
+		suffix := host[1:]
+		if strings.Contains(suffix, "*") || len(suffix) < 2 {
+			return originPattern{}, fmt.Errorf("cors: invalid wildcard origin %q", raw)
+		}
+		return originPattern{scheme: scheme, host: suffix, wildcard: true}, nil
+	}
+	if strings.Contains(host, "*") {
+		return originPattern{}, fmt.Errorf(`cors: invalid origin %q, "*" is only allowed as the first label`, raw)
+	}
+	return originPattern{scheme: scheme, host: host}, nil
+}
@@ -0,0 +1,260 @@
+package cors
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func mustNew(t *testing.T, opts Options) *Policy {
+	t.Helper()
+	p, err := New(opts)
+	if err != nil {
+		t.Fatalf("New(%+v): %v", opts, err)
+	}
+	return p
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+		is      error
+	}{
+		{name: "empty", opts: Options{}},
+		{name: "exact and wildcard", opts: Options{AllowedOrigins: []string{"https://disco.app", "https://*.disco.app", "http://localhost:3000/"}}},
+		{name: "any origin", opts: Options{AllowedOrigins: []string{"*"}}},
+		{name: "any origin with credentials", opts: Options{AllowedOrigins: []string{"*"}, AllowCredentials: true}, wantErr: true, is: ErrWildcardCredentials},
+		{name: "wildcard subdomain with credentials", opts: Options{AllowedOrigins: []string{"https://*.disco.app"}, AllowCredentials: true}},
+		{name: "missing scheme", opts: Options{AllowedOrigins: []string{"disco.app"}}, wantErr: true},
+		{name: "path", opts: Options{AllowedOrigins: []string{"https://disco.app/app"}}, wantErr: true},
+		{name: "wildcard in middle", opts: Options{AllowedOrigins: []string{"https://app.*.disco.app"}}, wantErr: true},
+		{name: "double wildcard", opts: Options{AllowedOrigins: []string{"https://*.*.disco.app"}}, wantErr: true},
+		{name: "bare wildcard label", opts: Options{AllowedOrigins: []string{"https://*."}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Fatalf("New() error = %v, want %v", err, tt.is)
+			}
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	p := mustNew(t, Options{AllowedOrigins: []string{
+		"https://disco.app",
+		"https://*.disco.app",
+		"http://localhost:3000",
+	}})
+	anyOrigin := mustNew(t, Options{AllowedOrigins: []string{"*"}})
+	none := mustNew(t, Options{})
+
+	tests := []struct {
+		name   string
+		policy *Policy
+		origin string
+		want   bool
+	}{
+		{"exact", p, "https://disco.app", true},
+		{"exact is case insensitive", p, "HTTPS://Disco.App", true},
+		{"exact with port", p, "http://localhost:3000", true},
+		{"wrong port", p, "http://localhost:3001", false},
+		{"default port not implied", p, "https://disco.app:443", false},
+		{"wrong scheme", p, "http://disco.app", false},
+		{"wildcard subdomain", p, "https://app.disco.app", true},
+		{"wildcard nested subdomain", p, "https://eu.admin.disco.app", true},
+		{"wildcard wrong scheme", p, "http://app.disco.app", false},
+		{"wildcard does not match suffix lookalike", p, "https://evildisco.app", false},
+		{"wildcard does not match other domain", p, "https://disco.app.evil.com", false},
+		{"unknown origin", p, "https://example.com", false},
+		{"null origin", p, "null", false},
+		{"malformed origin", p, "://disco.app", false},
+		{"any origin", anyOrigin, "https://example.com", true},
+		{"no origins configured", none, "https://disco.app", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Allowed("/api/v1/users", tt.origin); got != tt.want {
+				t.Errorf("Allowed(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverride(t *testing.T) {
+	base := mustNew(t, Options{AllowedOrigins: []string{"https://disco.app"}})
+	p, err := base.ParseRoutes([]string{
+		"/api/v1/admin=https://admin.disco.app",
+		"/api=https://*.disco.app https://disco.app",
+	})
+	if err != nil {
+		t.Fatalf("ParseRoutes: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		origin string
+		want   bool
+	}{
+		{"default policy", "/health", "https://disco.app", true},
+		{"default policy denies subdomain", "/health", "https://app.disco.app", false},
+		{"shorter prefix", "/api/v1/users", "https://app.disco.app", true},
+		{"longest prefix wins", "/api/v1/admin/users", "https://admin.disco.app", true},
+		{"longest prefix replaces origins", "/api/v1/admin/users", "https://disco.app", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Allowed(tt.path, tt.origin); got != tt.want {
+				t.Errorf("Allowed(%q, %q) = %v, want %v", tt.path, tt.origin, got, tt.want)
+			}
+		})
+	}
+
+	if base.Allowed("/api/v1/users", "https://app.disco.app") {
+		t.Error("Override modified the original policy")
+	}
+	for _, spec := range []string{"/api", "api=https://disco.app", "/api=*"} {
+		if _, err := mustNew(t, Options{AllowCredentials: true}).ParseRoutes([]string{spec}); err == nil {
+			t.Errorf("ParseRoutes(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestHandle(t *testing.T) {
+	credentialed := mustNew(t, Options{
+		AllowedOrigins:   []string{"https://*.disco.app"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+	anyOrigin := mustNew(t, Options{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}})
+
+	tests := []struct {
+		name        string
+		policy      *Policy
+		method      string
+		origin      string
+		preflight   bool
+		wantHandled bool
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			name: "allowed request", policy: credentialed, method: http.MethodGet, origin: "https://app.disco.app",
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.disco.app",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "X-Request-ID",
+				"Access-Control-Allow-Methods":     "",
+			},
+		},
+		{
+			name: "denied request", policy: credentialed, method: http.MethodGet, origin: "https://example.com",
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+		{
+			name: "same-origin request", policy: credentialed, method: http.MethodGet,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name: "allowed preflight", policy: credentialed, method: http.MethodOptions, origin: "https://app.disco.app", preflight: true,
+			wantHandled: true, wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.disco.app",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "GET, POST",
+				"Access-Control-Allow-Headers":     "Authorization, Content-Type",
+				"Access-Control-Max-Age":           "600",
+			},
+		},
+		{
+			name: "denied preflight", policy: credentialed, method: http.MethodOptions, origin: "https://example.com", preflight: true,
+			wantHandled: true, wantStatus: http.StatusForbidden,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name: "any origin answers with star", policy: anyOrigin, method: http.MethodOptions, origin: "https://example.com", preflight: true,
+			wantHandled: true, wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "*",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/v1/users", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			w := httptest.NewRecorder()
+
+			if got := tt.policy.Handle(w, r); got != tt.wantHandled {
+				t.Fatalf("Handle() = %v, want %v", got, tt.wantHandled)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			for name, want := range tt.wantHeaders {
+				if got := w.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			if got := w.Header().Values("Vary"); len(got) == 0 || got[0] != "Origin" {
+				t.Errorf("Vary = %v, want it to start with Origin", got)
+			}
+		})
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	p, err := mustNew(t, Options{AllowedOrigins: []string{"https://disco.app"}}).
+		ParseRoutes([]string{"/ws/partner=https://*.partner.com"})
+	if err != nil {
+		t.Fatalf("ParseRoutes: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		origin string
+		want   bool
+	}{
+		{"native client without origin", "/ws", "", true},
+		{"allowed browser origin", "/ws", "https://disco.app", true},
+		{"denied browser origin", "/ws", "https://evil.com", false},
+		{"override allows wildcard", "/ws/partner", "https://eu.partner.com", true},
+		{"override replaces default", "/ws/partner", "https://disco.app", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if got := p.CheckOrigin(r); got != tt.want {
+				t.Errorf("CheckOrigin(%q, %q) = %v, want %v", tt.path, tt.origin, got, tt.want)
+			}
+		})
+	}
+}
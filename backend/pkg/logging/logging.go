@@ -0,0 +1,79 @@
+// Package logging configures JSON structured logging and carries correlation
+// IDs through contexts so every log line written with a context includes them.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys shared by every service
+const (
+	RequestIDKey = "request_id"
+	ConnIDKey    = "conn_id"
+	UserIDKey    = "user_id"
+	TraceIDKey   = "trace_id"
+	SpanIDKey    = "span_id"
+)
+
+type ctxKey struct{}
+
+// New returns a JSON logger tagged with the service name. Attributes stored in
+// a context with With are added to records logged through the *Context methods.
+func New(service string, debug bool) *slog.Logger {
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	return slog.New(&contextHandler{handler}).With(slog.String("service", service))
+}
+
+// With returns a context carrying attrs in addition to any already present
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := Attrs(ctx)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, ctxKey{}, merged)
+}
+
+// Attrs returns the attributes stored in ctx
+func Attrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	return attrs
+}
+
+// contextHandler adds the attributes stored in the record's context, plus the
+// active trace and span IDs so log lines can be matched to traces
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(Attrs(ctx)...)
+	if ctx != nil {
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(
+				slog.String(TraceIDKey, sc.TraceID().String()),
+				slog.String(SpanIDKey, sc.SpanID().String()),
+			)
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,74 @@
+// Package metrics holds Prometheus instrumentation shared by the services
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHook records the latency and failures of every Redis command
+type RedisHook struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// NewRedisHook creates the Redis collectors under namespace and registers them with reg
+func NewRedisHook(namespace string, reg prometheus.Registerer) *RedisHook {
+	h := &RedisHook{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "redis",
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of Redis commands.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"command"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "redis",
+			Name:      "errors_total",
+			Help:      "Redis commands that failed, excluding cache misses.",
+		}, []string{"command"}),
+	}
+	reg.MustRegister(h.duration, h.errors)
+	return h
+}
+
+func (h *RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			h.errors.WithLabelValues("dial").Inc()
+		}
+		return conn, err
+	}
+}
+
+func (h *RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.observe(cmd.Name(), start, err)
+		return err
+	}
+}
+
+func (h *RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.observe("pipeline", start, err)
+		return err
+	}
+}
+
+func (h *RedisHook) observe(command string, start time.Time, err error) {
+	h.duration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, redis.Nil) {
+		h.errors.WithLabelValues(command).Inc()
+	}
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedisHook creates a client span for every Redis command and pipeline
+type RedisHook struct {
+	tracer trace.Tracer
+}
+
+// NewRedisHook creates a Redis tracing hook using the global tracer provider
+func NewRedisHook() *RedisHook {
+	return &RedisHook{tracer: otel.Tracer("disco/pkg/tracing/redis")}
+}
+
+func (h *RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, span := h.tracer.Start(ctx, "redis.dial", trace.WithSpanKind(trace.SpanKindClient))
+		defer span.End()
+
+		conn, err := next(ctx, network, addr)
+		recordError(span, err)
+		return conn, err
+	}
+}
+
+func (h *RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := h.tracer.Start(ctx, "redis."+cmd.Name(),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemRedis,
+				semconv.DBOperation(cmd.Name()),
+			),
+		)
+		defer span.End()
+
+		err := next(ctx, cmd)
+		recordError(span, err)
+		return err
+	}
+}
+
+func (h *RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := h.tracer.Start(ctx, "redis.pipeline",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemRedis,
+				attribute.Int("db.redis.num_cmd", len(cmds)),
+			),
+		)
+		defer span.End()
+
+		err := next(ctx, cmds)
+		recordError(span, err)
+		return err
+	}
+}
+
+// recordError marks the span failed; cache misses are not failures
+func recordError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
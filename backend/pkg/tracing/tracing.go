@@ -0,0 +1,126 @@
+// Package tracing configures OpenTelemetry trace export for the services
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Supported exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+// Options selects and configures the span exporter
+type Options struct {
+	// Exporter is one of none, stdout, file or otlp
+	Exporter string
+	// Endpoint is the OTLP/HTTP collector address, e.g. localhost:4318
+	Endpoint string
+	// Insecure disables TLS for the OTLP exporter
+	Insecure bool
+	// File receives one JSON span per line when Exporter is file
+	File string
+	// SampleRatio is the fraction of new traces recorded; child spans follow their parent
+	SampleRatio float64
+}
+
+// ValidExporter reports whether name is a supported exporter
+func ValidExporter(name string) bool {
+	switch name {
+	case ExporterNone, ExporterStdout, ExporterFile, ExporterOTLP:
+		return true
+	}
+	return false
+}
+
+// Setup installs the global tracer provider and W3C trace-context propagator.
+// The returned function flushes pending spans and must be called on shutdown.
+func Setup(ctx context.Context, service string, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if opts.Exporter == "" || opts.Exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closer, err := newExporter(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(service),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
+
+func newExporter(ctx context.Context, opts Options) (sdktrace.SpanExporter, io.Closer, error) {
+	switch opts.Exporter {
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, nil, err
+
+	case ExporterFile:
+		if opts.File == "" {
+			return nil, nil, errors.New("tracing: file exporter requires a file path")
+		}
+		f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("tracing: open %s: %w", opts.File, err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return exporter, f, nil
+
+	case ExporterOTLP:
+		clientOpts := []otlptracehttp.Option{}
+		if opts.Endpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, clientOpts...)
+		return exporter, nil, err
+	}
+
+	return nil, nil, fmt.Errorf("tracing: unknown exporter %q", opts.Exporter)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"disco/core-api/internal/config"
+)
+
+const configUsage = `usage: core-api config print [--redacted]`
+
+// runConfig implements the `core-api config` subcommands
+func runConfig(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redacted := fs.Bool("redacted", false, "mask secrets and URL passwords")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if err := cfg.Print(os.Stdout, *redacted); err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "\nwarning: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"disco/core-api/internal/config"
+	"disco/core-api/internal/database"
+	"disco/core-api/internal/export"
+	"disco/core-api/internal/services"
+	"disco/core-api/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+const exportUsage = `usage: core-api export <command>
+
+commands:
+  report -actor USER_ID -reference REF [-out FILE] REPORT_ID
+                 write a signed bundle for a safety report
+  alert -actor USER_ID -reference REF [-out FILE] ALERT_ID
+                 write a signed bundle for an emergency alert
+  verify [-pubkey FILE] BUNDLE [SIGNATURE]
+                 check a bundle's signature and file hashes; SIGNATURE
+                 defaults to BUNDLE.sig and FILE to the configured key
+  public-key     print the public key recipients verify bundles with`
+
+// runExport implements the `core-api export` subcommands. Verification
+// needs no database, so it works on any machine given the public key.
+func runExport(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(exportUsage)
+	}
+
+	switch args[0] {
+	case export.SubjectReport, export.SubjectAlert:
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		return runExportBundle(cfg, args[0], args[1:])
+
+	case "verify":
+		return runExportVerify(cfg, args[1:])
+
+	case "public-key":
+		key, err := loadExportKey(cfg)
+		if err != nil {
+			return err
+		}
+		pemKey, err := export.EncodePublicKey(key.Public().(ed25519.PublicKey))
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(pemKey)
+		return err
+	}
+	return errors.New(exportUsage)
+}
+
+// runExportBundle writes a bundle and its .sig file
+func runExportBundle(cfg *config.Config, kind string, args []string) error {
+	fs := flag.NewFlagSet("export "+kind, flag.ContinueOnError)
+	actor := fs.String("actor", "", "ID of the admin producing the export")
+	reference := fs.String("reference", "", "the requesting agency's case or request number")
+	out := fs.String("out", "", "bundle file (default <kind>-<id>.zip)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New(exportUsage)
+	}
+	id, err := uuid.Parse(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid %s ID %q", kind, fs.Arg(0))
+	}
+	actorID, err := uuid.Parse(*actor)
+	if err != nil {
+		return errors.New("-actor must be a user ID")
+	}
+	if *out == "" {
+		*out = kind + "-" + id.String() + ".zip"
+	}
+
+	key, err := loadExportKey(cfg)
+	if err != nil {
+		return err
+	}
+	db, err := database.Connect(cfg)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	ctx := context.Background()
+	store, err := storage.Open(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("open evidence storage: %w", err)
+	}
+	// History and chain verification only read the database
+	safety := services.NewSafetyService(db, nil, services.ReportLimits{}, nil, 0, nil)
+	exporter := services.NewExportService(db, store, safety, key)
+
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	result, err := exporter.Export(ctx, f, services.ExportRequest{
+		Kind:      kind,
+		ID:        id,
+		ActorID:   actorID,
+		Reference: *reference,
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*out)
+		return err
+	}
+	if err := os.WriteFile(*out+".sig", []byte(result.Signature+"\n"), 0o600); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %s and %s (%d files, key %s)\n", *out, *out+".sig", len(result.Manifest.Files), result.Manifest.KeyID)
+	for _, w := range result.Manifest.Warnings {
+		fmt.Printf("warning: %s\n", w)
+	}
+	return nil
+}
+
+// runExportVerify checks a bundle's detached signature, then its contents
+func runExportVerify(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("export verify", flag.ContinueOnError)
+	pubkey := fs.String("pubkey", "", "PEM public key (default: derived from the configured signing key)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return errors.New(exportUsage)
+	}
+	bundlePath := fs.Arg(0)
+	sigPath := bundlePath + ".sig"
+	if fs.NArg() == 2 {
+		sigPath = fs.Arg(1)
+	}
+
+	var pub ed25519.PublicKey
+	if *pubkey != "" {
+		var err error
+		if pub, err = export.LoadPublicKey(*pubkey); err != nil {
+			return err
+		}
+	} else {
+		key, err := loadExportKey(cfg)
+		if err != nil {
+			return err
+		}
+		pub = key.Public().(ed25519.PublicKey)
+	}
+
+	signature, err := os.ReadFile(sigPath)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := export.Verify(pub, f, string(signature)); err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	manifest, err := export.VerifyContents(f, info.Size())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Signature OK (key %s)\n", export.KeyID(pub))
+	fmt.Printf("%s %s, reference %q, generated %s by %s\n", manifest.Subject.Kind, manifest.Subject.ID,
+		manifest.Reference, manifest.GeneratedAt.Format("2006-01-02 15:04:05Z07:00"), manifest.GeneratedBy)
+	fmt.Printf("All %d files match the manifest\n", len(manifest.Files))
+	for _, w := range manifest.Warnings {
+		fmt.Printf("warning recorded at export: %s\n", w)
+	}
+	return nil
+}
+
+// loadExportKey loads the configured signing key, which must be set
+func loadExportKey(cfg *config.Config) (ed25519.PrivateKey, error) {
+	key, err := export.LoadPrivateKey(cfg.ExportSigningKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, services.ErrExportDisabled
+	}
+	return key, nil
+}
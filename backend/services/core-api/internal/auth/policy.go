@@ -0,0 +1,54 @@
+package auth
+
+import "disco/core-api/internal/models"
+
+// Permission names an action that routes can require
+type Permission string
+
+const (
+	PermReportsRead     Permission = "reports:read"
+	PermReportsModerate Permission = "reports:moderate"
+	PermUsersManage     Permission = "users:manage"
+	PermRecordsExport   Permission = "records:export"
+	PermResponsesManage Permission = "responses:manage"
+)
+
+// rolePermissions grants permissions to roles. Regular users hold no
+// extra permissions; they may only act on their own resources.
+var rolePermissions = map[models.Role][]Permission{
+	models.RoleSupport: {
+		PermReportsRead,
+	},
+	models.RoleModerator: {
+		PermReportsRead,
+		PermReportsModerate,
+	},
+	models.RoleAdmin: {
+		PermReportsRead,
+		PermReportsModerate,
+		PermUsersManage,
+		PermRecordsExport,
+		PermResponsesManage,
+	},
+}
+
+// Allowed reports whether the role has been granted the permission
+func Allowed(role models.Role, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RolesWith lists the roles that have been granted the permission
+func RolesWith(perm Permission) []models.Role {
+	var roles []models.Role
+	for role := range rolePermissions {
+		if Allowed(role, perm) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
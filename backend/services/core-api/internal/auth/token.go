@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"disco/core-api/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+
+	issuer = "disco-core-api"
+
+	// ScopeAppeal limits a token to the routes a locked-out user may still
+	// reach: their sanctions, their appeals and logout
+	ScopeAppeal = "appeal"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenRevoked = errors.New("token revoked")
+	ErrTokenReused  = errors.New("refresh token reused")
+)
+
+// Claims are the JWT claims carried by access and refresh tokens
+type Claims struct {
+	jwt.RegisteredClaims
+	Type   string      `json:"typ"`
+	Family uuid.UUID   `json:"fam"`
+	Role   models.Role `json:"role,omitempty"`
+	Scope  string      `json:"scope,omitempty"`
+}
+
+// UserID returns the subject of the token as a UUID
+func (c *Claims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+// TokenPair is returned to clients on login and refresh. Scoped tokens
+// come without a refresh token.
+type TokenPair struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Scope        string    `json:"scope,omitempty"`
+}
+
+// TokenService issues, validates and revokes signed tokens.
+// Refresh tokens are single use: each refresh rotates the token and
+// presenting an already used one revokes the whole token family.
+type TokenService struct {
+	secret     []byte
+	redis      *redis.Client
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewTokenService creates a new token service
+func NewTokenService(secret string, rdb *redis.Client, accessTTL, refreshTTL time.Duration) *TokenService {
+	return &TokenService{
+		secret:     []byte(secret),
+		redis:      rdb,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// IssuePair starts a new token family for the user
+func (s *TokenService) IssuePair(ctx context.Context, userID uuid.UUID, role models.Role) (*TokenPair, error) {
+	return s.issuePair(ctx, userID, role, uuid.New())
+}
+
+// IssueScoped issues a short-lived access token limited to scope. It carries
+// no role and cannot be refreshed, so the user has to sign in again for another.
+func (s *TokenService) IssueScoped(userID uuid.UUID, scope string) (*TokenPair, error) {
+	access, claims, err := s.sign(userID, uuid.New(), "", scope, tokenTypeAccess, time.Now(), s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken: access,
+		ExpiresAt:   claims.ExpiresAt.Time,
+		Scope:       scope,
+	}, nil
+}
+
+// Rotate issues a new token pair in the family of a refresh token
+// previously returned by ConsumeRefresh
+func (s *TokenService) Rotate(ctx context.Context, refreshed *Claims, role models.Role) (*TokenPair, error) {
+	userID, err := refreshed.UserID()
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return s.issuePair(ctx, userID, role, refreshed.Family)
+}
+
+// ConsumeRefresh validates a refresh token and marks it as used. The caller
+// is expected to follow up with Rotate once it has re-checked the user.
+func (s *TokenService) ConsumeRefresh(ctx context.Context, refreshToken string) (*Claims, error) {
+	claims, err := s.parse(refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkRevoked(ctx, claims, familyKey(claims.Family)); err != nil {
+		return nil, err
+	}
+
+	// GETDEL makes the token single use even under concurrent refreshes
+	if err := s.redis.GetDel(ctx, refreshKey(claims.ID)).Err(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			if err := s.revokeFamily(ctx, claims.Family); err != nil {
+				return nil, err
+			}
+			return nil, ErrTokenReused
+		}
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// Validate parses an access token and checks it against the revocation list
+func (s *TokenService) Validate(ctx context.Context, accessToken string) (*Claims, error) {
+	claims, err := s.parse(accessToken, tokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkRevoked(ctx, claims, revokedKey(claims.ID), familyKey(claims.Family)); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// Revoke invalidates an access token and every refresh token in its family
+func (s *TokenService) Revoke(ctx context.Context, claims *Claims) error {
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl > 0 {
+		if err := s.redis.Set(ctx, revokedKey(claims.ID), 1, ttl).Err(); err != nil {
+			return err
+		}
+	}
+	return s.revokeFamily(ctx, claims.Family)
+}
+
+// RevokeUser invalidates every token issued to the user up to now, for when
+// the claims they carry, such as the role, no longer hold. Tokens issued in
+// the same second are revoked too, so the user may have to sign in again.
+func (s *TokenService) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	return s.redis.Set(ctx, userKey(userID.String()), time.Now().Unix(), s.refreshTTL).Err()
+}
+
+func (s *TokenService) issuePair(ctx context.Context, userID uuid.UUID, role models.Role, family uuid.UUID) (*TokenPair, error) {
+	now := time.Now()
+
+	access, accessClaims, err := s.sign(userID, family, role, "", tokenTypeAccess, now, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, refreshClaims, err := s.sign(userID, family, "", "", tokenTypeRefresh, now, s.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.redis.Set(ctx, refreshKey(refreshClaims.ID), userID.String(), s.refreshTTL).Err(); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresAt:    accessClaims.ExpiresAt.Time,
+	}, nil
+}
+
+func (s *TokenService) sign(userID, family uuid.UUID, role models.Role, scope, tokenType string, now time.Time, ttl time.Duration) (string, *Claims, error) {
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    issuer,
+			Subject:   userID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Type:   tokenType,
+		Family: family,
+		Role:   role,
+		Scope:  scope,
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return "", nil, fmt.Errorf("sign token: %w", err)
+	}
+	return signed, claims, nil
+}
+
+func (s *TokenService) parse(token, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// checkRevoked rejects claims whose token, family or user has been revoked.
+// keys are the token's revocation entries.
+func (s *TokenService) checkRevoked(ctx context.Context, claims *Claims, keys ...string) error {
+	pipe := s.redis.Pipeline()
+	revoked := pipe.Exists(ctx, keys...)
+	cutoff := pipe.Get(ctx, userKey(claims.Subject))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if revoked.Val() > 0 {
+		return ErrTokenRevoked
+	}
+	if before, err := cutoff.Int64(); err == nil && claims.IssuedAt != nil && claims.IssuedAt.Unix() <= before {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+func (s *TokenService) revokeFamily(ctx context.Context, family uuid.UUID) error {
+	return s.redis.Set(ctx, familyKey(family), 1, s.refreshTTL).Err()
+}
+
+func refreshKey(id string) string {
+	return "auth:refresh:" + id
+}
+
+func revokedKey(id string) string {
+	return "auth:revoked:" + id
+}
+
+func userKey(subject string) string {
+	return "auth:user:" + subject
+}
+
+func familyKey(family uuid.UUID) string {
+	return "auth:family:" + family.String()
+}
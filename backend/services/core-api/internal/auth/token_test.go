@@ -0,0 +1,310 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"disco/core-api/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const testSecret = "test-secret"
+
+func newTestTokenService(t *testing.T) (*TokenService, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return NewTokenService(testSecret, rdb, 15*time.Minute, 24*time.Hour), mr
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestTokenService(t)
+	userID := uuid.New()
+
+	pair, err := s.IssuePair(ctx, userID, models.RoleModerator)
+	if err != nil {
+		t.Fatalf("IssuePair: %v", err)
+	}
+
+	other := NewTokenService("other-secret", s.redis, s.accessTTL, s.refreshTTL)
+	forged, _, err := other.sign(userID, uuid.New(), models.RoleAdmin, "", tokenTypeAccess, time.Now(), time.Minute)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	expired, _, err := s.sign(userID, uuid.New(), models.RoleUser, "", tokenTypeAccess, time.Now().Add(-time.Hour), time.Minute)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   userID.String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+		Type: tokenTypeAccess,
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("sign none: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"access token", pair.AccessToken, nil},
+		{"refresh token used as access", pair.RefreshToken, ErrInvalidToken},
+		{"signed with another secret", forged, ErrInvalidToken},
+		{"expired", expired, ErrInvalidToken},
+		{"unsigned", unsigned, ErrInvalidToken},
+		{"tampered", pair.AccessToken[:len(pair.AccessToken)-2] + "xx", ErrInvalidToken},
+		{"garbage", "not-a-token", ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := s.Validate(ctx, tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got, _ := claims.UserID(); got != userID {
+				t.Errorf("UserID() = %v, want %v", got, userID)
+			}
+			if claims.Role != models.RoleModerator {
+				t.Errorf("Role = %q, want %q", claims.Role, models.RoleModerator)
+			}
+		})
+	}
+}
+
+func TestRefreshRotation(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestTokenService(t)
+	userID := uuid.New()
+
+	first, err := s.IssuePair(ctx, userID, models.RoleUser)
+	if err != nil {
+		t.Fatalf("IssuePair: %v", err)
+	}
+	if _, err := s.ConsumeRefresh(ctx, first.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("ConsumeRefresh(access token) error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	claims, err := s.ConsumeRefresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("ConsumeRefresh: %v", err)
+	}
+	second, err := s.Rotate(ctx, claims, models.RoleUser)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Fatal("Rotate returned the same tokens")
+	}
+
+	rotated, err := s.Validate(ctx, second.AccessToken)
+	if err != nil {
+		t.Fatalf("Validate(rotated access): %v", err)
+	}
+	if rotated.Family != claims.Family {
+		t.Errorf("rotated family = %v, want %v", rotated.Family, claims.Family)
+	}
+
+	// A family started by another login is not affected by this one
+	otherLogin, err := s.IssuePair(ctx, userID, models.RoleUser)
+	if err != nil {
+		t.Fatalf("IssuePair: %v", err)
+	}
+
+	// Replaying the consumed token means it leaked: the whole family goes
+	if _, err := s.ConsumeRefresh(ctx, first.RefreshToken); !errors.Is(err, ErrTokenReused) {
+		t.Fatalf("replayed ConsumeRefresh error = %v, want %v", err, ErrTokenReused)
+	}
+
+	tests := []struct {
+		name    string
+		check   func() error
+		wantErr error
+	}{
+		{"rotated refresh token", func() error {
+			_, err := s.ConsumeRefresh(ctx, second.RefreshToken)
+			return err
+		}, ErrTokenRevoked},
+		{"rotated access token", func() error {
+			_, err := s.Validate(ctx, second.AccessToken)
+			return err
+		}, ErrTokenRevoked},
+		{"original access token", func() error {
+			_, err := s.Validate(ctx, first.AccessToken)
+			return err
+		}, ErrTokenRevoked},
+		{"other login access token", func() error {
+			_, err := s.Validate(ctx, otherLogin.AccessToken)
+			return err
+		}, nil},
+		{"other login refresh token", func() error {
+			_, err := s.ConsumeRefresh(ctx, otherLogin.RefreshToken)
+			return err
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.check(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefreshSingleUseUnderConcurrency(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestTokenService(t)
+
+	pair, err := s.IssuePair(ctx, uuid.New(), models.RoleUser)
+	if err != nil {
+		t.Fatalf("IssuePair: %v", err)
+	}
+
+	const attempts = 8
+	results := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		go func() {
+			_, err := s.ConsumeRefresh(ctx, pair.RefreshToken)
+			results <- err
+		}()
+	}
+
+	var succeeded int
+	for i := 0; i < attempts; i++ {
+		err := <-results
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrTokenReused), errors.Is(err, ErrTokenRevoked):
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d refreshes succeeded, want exactly 1", succeeded)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	s, mr := newTestTokenService(t)
+
+	pair, err := s.IssuePair(ctx, uuid.New(), models.RoleUser)
+	if err != nil {
+		t.Fatalf("IssuePair: %v", err)
+	}
+	claims, err := s.Validate(ctx, pair.AccessToken)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if err := s.Revoke(ctx, claims); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+
+	if _, err := s.Validate(ctx, pair.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("Validate after Revoke error = %v, want %v", err, ErrTokenRevoked)
+	}
+	if _, err := s.ConsumeRefresh(ctx, pair.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ConsumeRefresh after Revoke error = %v, want %v", err, ErrTokenRevoked)
+	}
+
+	// Revocation entries expire with the tokens they cover
+	if ttl := mr.TTL(revokedKey(claims.ID)); ttl <= 0 || ttl > s.accessTTL {
+		t.Errorf("revoked access TTL = %v, want within (0, %v]", ttl, s.accessTTL)
+	}
+	if ttl := mr.TTL(familyKey(claims.Family)); ttl != s.refreshTTL {
+		t.Errorf("revoked family TTL = %v, want %v", ttl, s.refreshTTL)
+	}
+}
+
+func TestIssueScoped(t *testing.T) {
+	ctx := context.Background()
+	s, mr := newTestTokenService(t)
+	userID := uuid.New()
+
+	pair, err := s.IssueScoped(userID, ScopeAppeal)
+	if err != nil {
+		t.Fatalf("IssueScoped: %v", err)
+	}
+	if pair.RefreshToken != "" || pair.Scope != ScopeAppeal {
+		t.Fatalf("IssueScoped() = %+v, want an appeal-scoped access token only", pair)
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("IssueScoped stored %v, want nothing to refresh", keys)
+	}
+
+	claims, err := s.Validate(ctx, pair.AccessToken)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if got, _ := claims.UserID(); got != userID {
+		t.Errorf("UserID() = %v, want %v", got, userID)
+	}
+	if claims.Scope != ScopeAppeal || claims.Role != "" {
+		t.Errorf("Scope, Role = %q, %q, want %q and no role", claims.Scope, claims.Role, ScopeAppeal)
+	}
+
+	if err := s.Revoke(ctx, claims); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := s.Validate(ctx, pair.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("Validate after Revoke error = %v, want %v", err, ErrTokenRevoked)
+	}
+}
+
+func TestRevokeUser(t *testing.T) {
+	ctx := context.Background()
+	s, mr := newTestTokenService(t)
+	userID := uuid.New()
+
+	pair, err := s.IssuePair(ctx, userID, models.RoleModerator)
+	if err != nil {
+		t.Fatalf("IssuePair: %v", err)
+	}
+	other, err := s.IssuePair(ctx, uuid.New(), models.RoleModerator)
+	if err != nil {
+		t.Fatalf("IssuePair: %v", err)
+	}
+	if err := s.RevokeUser(ctx, userID); err != nil {
+		t.Fatalf("RevokeUser: %v", err)
+	}
+
+	if _, err := s.Validate(ctx, pair.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("Validate after RevokeUser error = %v, want %v", err, ErrTokenRevoked)
+	}
+	if _, err := s.ConsumeRefresh(ctx, pair.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ConsumeRefresh after RevokeUser error = %v, want %v", err, ErrTokenRevoked)
+	}
+	if _, err := s.Validate(ctx, other.AccessToken); err != nil {
+		t.Errorf("Validate for another user = %v, want nil", err)
+	}
+	if ttl := mr.TTL(userKey(userID.String())); ttl != s.refreshTTL {
+		t.Errorf("user revocation TTL = %v, want %v", ttl, s.refreshTTL)
+	}
+
+	// Tokens issued after the cutoff are accepted
+	mr.Set(userKey(userID.String()), strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10))
+	fresh, err := s.IssuePair(ctx, userID, models.RoleUser)
+	if err != nil {
+		t.Fatalf("IssuePair: %v", err)
+	}
+	if _, err := s.Validate(ctx, fresh.AccessToken); err != nil {
+		t.Errorf("Validate for a token issued after the cutoff = %v, want nil", err)
+	}
+}
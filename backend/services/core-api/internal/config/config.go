@@ -1,36 +1,281 @@
 package config
 
 import (
-	"os"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"disco/core-api/internal/models"
+	sharedconfig "disco/pkg/config"
+	"disco/pkg/cors"
+	"disco/pkg/tracing"
 )
 
+// minSecretLength is the shortest JWT secret accepted outside debug mode
+const minSecretLength = 32
+
+// Config is loaded from defaults, then the YAML file given by --config or
+// CONFIG_FILE, then environment variables, then command-line flags
 type Config struct {
-	DBHost     string
-	DBPort     string
-	DBName     string
-	DBUser     string
-	DBPassword string
-	RedisURL   string
-	JWTSecret  string
-	Debug      bool
+	DBHost     string `yaml:"db_host" env:"DB_HOST" flag:"db-host" default:"localhost"`
+	DBPort     string `yaml:"db_port" env:"DB_PORT" flag:"db-port" default:"5432"`
+	DBName     string `yaml:"db_name" env:"DB_NAME" flag:"db-name" default:"disco_core"`
+	DBUser     string `yaml:"db_user" env:"DB_USER" flag:"db-user" default:"disco_user"`
+	DBPassword string `yaml:"db_password" env:"DB_PASSWORD" default:"disco_password" secret:"true"`
+	RedisURL   string `yaml:"redis_url" env:"REDIS_URL" flag:"redis-url" default:"redis://localhost:6379"`
+	JWTSecret  string `yaml:"jwt_secret" env:"JWT_SECRET" default:"default-secret-key" secret:"true"`
+	Debug      bool   `yaml:"debug" env:"DEBUG" flag:"debug" default:"false" usage:"enable debug mode; allows default secrets"`
+	// MigrateOnStart applies pending migrations before the server starts
+	MigrateOnStart bool `yaml:"migrate_on_start" env:"MIGRATE_ON_START" flag:"migrate-on-start" default:"true"`
+
+	// Database pool limits
+	DBMaxOpenConns    int           `yaml:"db_max_open_conns" env:"DB_MAX_OPEN_CONNS" flag:"db-max-open-conns" default:"25"`
+	DBMaxIdleConns    int           `yaml:"db_max_idle_conns" env:"DB_MAX_IDLE_CONNS" flag:"db-max-idle-conns" default:"5"`
+	DBConnMaxLifetime time.Duration `yaml:"db_conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" flag:"db-conn-max-lifetime" default:"30m"`
+
+	// Token lifetimes
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl" env:"ACCESS_TOKEN_TTL" flag:"access-token-ttl" default:"15m"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL" flag:"refresh-token-ttl" default:"720h"`
+
+	// HTTP server
+	ServerAddr      string        `yaml:"server_addr" env:"SERVER_ADDR" flag:"addr" default:":8080"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" flag:"read-timeout" default:"15s"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" flag:"write-timeout" default:"15s"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" flag:"idle-timeout" default:"60s"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" default:"30s"`
+	DrainDelay      time.Duration `yaml:"drain_delay" env:"SERVER_DRAIN_DELAY" flag:"drain-delay" default:"5s" usage:"how long readiness reports draining before the server stops accepting connections"`
+	MaxBodyBytes    int64         `yaml:"max_body_bytes" env:"SERVER_MAX_BODY_BYTES" flag:"max-body-bytes" default:"1048576"`
+
+	// Tracing
+	TracingExporter    string  `yaml:"tracing_exporter" env:"TRACING_EXPORTER" flag:"tracing-exporter" default:"none" usage:"span exporter: none, stdout, file or otlp"`
+	TracingEndpoint    string  `yaml:"tracing_endpoint" env:"TRACING_ENDPOINT" flag:"tracing-endpoint" usage:"OTLP/HTTP collector address"`
+	TracingInsecure    bool    `yaml:"tracing_insecure" env:"TRACING_INSECURE" flag:"tracing-insecure" default:"false"`
+	TracingFile        string  `yaml:"tracing_file" env:"TRACING_FILE" flag:"tracing-file" usage:"span output file for the file exporter"`
+	TracingSampleRatio float64 `yaml:"tracing_sample_ratio" env:"TRACING_SAMPLE_RATIO" flag:"tracing-sample-ratio" default:"1"`
+
+	// Cross-origin policy, also applied to websocket upgrades
+	CORSAllowedOrigins   []string      `yaml:"cors_allowed_origins" env:"CORS_ALLOWED_ORIGINS" flag:"cors-allowed-origins" default:"http://localhost:3000" usage:"origins allowed to call the API; https://*.example.com matches subdomains"`
+	CORSAllowedMethods   []string      `yaml:"cors_allowed_methods" env:"CORS_ALLOWED_METHODS" flag:"cors-allowed-methods" default:"GET,HEAD,POST,PUT,PATCH,DELETE,OPTIONS"`
+	CORSAllowedHeaders   []string      `yaml:"cors_allowed_headers" env:"CORS_ALLOWED_HEADERS" flag:"cors-allowed-headers" default:"Content-Type,Authorization,X-Request-ID,Tus-Resumable,Upload-Length,Upload-Offset,Upload-Metadata"`
+	CORSExposedHeaders   []string      `yaml:"cors_exposed_headers" env:"CORS_EXPOSED_HEADERS" flag:"cors-exposed-headers" default:"X-Request-ID,Location,Tus-Resumable,Tus-Version,Tus-Extension,Tus-Max-Size,Upload-Offset,Upload-Length,Upload-Expires,X-Evidence-ID,X-Bundle-Signature,X-Bundle-Key-ID"`
+	CORSAllowCredentials bool          `yaml:"cors_allow_credentials" env:"CORS_ALLOW_CREDENTIALS" flag:"cors-allow-credentials" default:"false"`
+	CORSMaxAge           time.Duration `yaml:"cors_max_age" env:"CORS_MAX_AGE" flag:"cors-max-age" default:"10m" usage:"how long browsers may cache preflight responses"`
+	CORSRouteOrigins     []string      `yaml:"cors_route_origins" env:"CORS_ROUTE_ORIGINS" flag:"cors-route-origins" usage:"per-route origin overrides as /prefix=origin origin"`
+
+	// Evidence storage
+	StorageBackend string `yaml:"storage_backend" env:"STORAGE_BACKEND" flag:"storage-backend" default:"local" usage:"blob store for evidence: local or s3"`
+	StorageDir     string `yaml:"storage_dir" env:"STORAGE_DIR" flag:"storage-dir" default:"./data/evidence" usage:"directory used by the local backend"`
+	S3Endpoint     string `yaml:"s3_endpoint" env:"S3_ENDPOINT" flag:"s3-endpoint" usage:"S3-compatible endpoint as host[:port], e.g. localhost:9000 for MinIO"`
+	S3Region       string `yaml:"s3_region" env:"S3_REGION" flag:"s3-region" default:"us-east-1"`
+	S3Bucket       string `yaml:"s3_bucket" env:"S3_BUCKET" flag:"s3-bucket" default:"disco-evidence"`
+	S3AccessKey    string `yaml:"s3_access_key" env:"S3_ACCESS_KEY" secret:"optional"`
+	S3SecretKey    string `yaml:"s3_secret_key" env:"S3_SECRET_KEY" secret:"optional"`
+	S3UseSSL       bool   `yaml:"s3_use_ssl" env:"S3_USE_SSL" flag:"s3-use-ssl" default:"true"`
+
+	// Evidence uploads
+	EvidenceMaxBytes     int64         `yaml:"evidence_max_bytes" env:"EVIDENCE_MAX_BYTES" flag:"evidence-max-bytes" default:"20971520" usage:"largest evidence file accepted"`
+	EvidenceAllowedTypes []string      `yaml:"evidence_allowed_types" env:"EVIDENCE_ALLOWED_TYPES" flag:"evidence-allowed-types" default:"image/jpeg,image/png,image/gif,image/webp,video/mp4,video/webm,audio/mpeg,audio/wave,application/ogg,application/pdf,text/plain" usage:"content types accepted after sniffing"`
+	EvidenceURLTTL       time.Duration `yaml:"evidence_url_ttl" env:"EVIDENCE_URL_TTL" flag:"evidence-url-ttl" default:"15m" usage:"lifetime of signed evidence download URLs"`
+
+	// Resumable (tus) uploads
+	ResumableMaxBytes     int64         `yaml:"resumable_max_bytes" env:"RESUMABLE_MAX_BYTES" flag:"resumable-max-bytes" default:"524288000" usage:"largest resumable upload accepted"`
+	ResumableUploadTTL    time.Duration `yaml:"resumable_upload_ttl" env:"RESUMABLE_UPLOAD_TTL" flag:"resumable-upload-ttl" default:"24h" usage:"how long an unfinished upload can be resumed"`
+	ResumableChunkTimeout time.Duration `yaml:"resumable_chunk_timeout" env:"RESUMABLE_CHUNK_TIMEOUT" flag:"resumable-chunk-timeout" default:"5m" usage:"read deadline for a single PATCH; replaces read_timeout on that route"`
+
+	// Report abuse limits; 0 disables a limit
+	ReportDuplicateWindow time.Duration `yaml:"report_duplicate_window" env:"REPORT_DUPLICATE_WINDOW" flag:"report-duplicate-window" default:"24h" usage:"window in which a report about the same user and type is a duplicate"`
+	ReportPerTargetLimit  int           `yaml:"report_per_target_limit" env:"REPORT_PER_TARGET_LIMIT" flag:"report-per-target-limit" default:"3" usage:"reports one reporter may file against one user per duplicate window"`
+	ReportRateLimit       int           `yaml:"report_rate_limit" env:"REPORT_RATE_LIMIT" flag:"report-rate-limit" default:"10" usage:"non-emergency reports one reporter may file per rate window"`
+	ReportRateWindow      time.Duration `yaml:"report_rate_window" env:"REPORT_RATE_WINDOW" flag:"report-rate-window" default:"1h"`
+
+	// Review SLAs by minimum priority
+	ReportSLATiers         []string      `yaml:"report_sla_tiers" env:"REPORT_SLA_TIERS" flag:"report-sla-tiers" default:"100=15m,90=1h,50=4h,30=24h,0=72h" usage:"review deadlines as priority=duration; the highest tier at or below a report's priority applies"`
+	ReportSLACheckInterval time.Duration `yaml:"report_sla_check_interval" env:"REPORT_SLA_CHECK_INTERVAL" flag:"report-sla-check-interval" default:"1m"`
+
+	// Report assignment
+	ReportAssignment string        `yaml:"report_assignment" env:"REPORT_ASSIGNMENT" flag:"report-assignment" default:"off" usage:"assign new reports automatically: off, round_robin or least_loaded"`
+	ReportClaimTTL   time.Duration `yaml:"report_claim_ttl" env:"REPORT_CLAIM_TTL" flag:"report-claim-ttl" default:"15m" usage:"how long a moderator's claim on a report lasts unless renewed"`
+
+	// SanctionCacheTTL is how long a user's sanction lookup is reused; 0 disables caching
+	SanctionCacheTTL time.Duration `yaml:"sanction_cache_ttl" env:"SANCTION_CACHE_TTL" flag:"sanction-cache-ttl" default:"5s" usage:"how long each instance reuses a user's sanction status; sanctions issued elsewhere take up to this long to apply"`
+
+	// CaseMergeWindow is how close in time reports about the same user must be to be suggested for one case
+	CaseMergeWindow time.Duration `yaml:"case_merge_window" env:"CASE_MERGE_WINDOW" flag:"case-merge-window" default:"72h" usage:"reports about the same user filed this close together are suggested for the same case"`
+
+	// EscalationRulesFile holds the auto-escalation rules; empty disables them
+	EscalationRulesFile string `yaml:"escalation_rules_file" env:"ESCALATION_RULES_FILE" flag:"escalation-rules-file" usage:"YAML file of report auto-escalation rules"`
+	// AutoSuspensionDuration bounds suspensions placed by escalation rules
+	AutoSuspensionDuration time.Duration `yaml:"auto_suspension_duration" env:"AUTO_SUSPENSION_DURATION" flag:"auto-suspension-duration" default:"24h" usage:"how long an escalation rule's suspension lasts unless a moderator confirms it with a new duration"`
+
+	// ExportSigningKeyFile signs law-enforcement export bundles; empty disables exports
+	ExportSigningKeyFile string `yaml:"export_signing_key_file" env:"EXPORT_SIGNING_KEY_FILE" flag:"export-signing-key-file" usage:"PKCS #8 PEM Ed25519 key, e.g. from openssl genpkey -algorithm ed25519"`
 }
 
-func Load() (*Config, error) {
-	return &Config{
-		DBHost:     getEnvOrDefault("DB_HOST", "localhost"),
-		DBPort:     getEnvOrDefault("DB_PORT", "5432"),
-		DBName:     getEnvOrDefault("DB_NAME", "disco_core"),
-		DBUser:     getEnvOrDefault("DB_USER", "disco_user"),
-		DBPassword: getEnvOrDefault("DB_PASSWORD", "disco_password"),
-		RedisURL:   getEnvOrDefault("REDIS_URL", "redis://localhost:6379"),
-		JWTSecret:  getEnvOrDefault("JWT_SECRET", "default-secret-key"),
-		Debug:      getEnvOrDefault("DEBUG", "false") == "true",
-	}, nil
+// Load builds the configuration from every layer and returns the remaining
+// command-line arguments. It does not validate; call Validate before use.
+func Load(args []string) (*Config, []string, error) {
+	cfg := &Config{}
+	rest, err := sharedconfig.Load(cfg, "core-api", args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, rest, nil
 }
 
-func getEnvOrDefault(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// Validate checks limits and refuses default secrets unless Debug is on
+func (c *Config) Validate() error {
+	var problems []string
+
+	if !c.Debug {
+		if insecure := sharedconfig.InsecureSecrets(c); len(insecure) > 0 {
+			problems = append(problems, fmt.Sprintf("default or empty secrets: %s (set them or enable debug)", strings.Join(insecure, ", ")))
+		}
+		if len(c.JWTSecret) < minSecretLength {
+			problems = append(problems, fmt.Sprintf("jwt_secret must be at least %d characters", minSecretLength))
+		}
+	}
+
+	if c.ServerAddr == "" {
+		problems = append(problems, "server_addr is required")
+	}
+	if c.DBMaxOpenConns < 1 {
+		problems = append(problems, "db_max_open_conns must be positive")
+	}
+	if c.DBMaxIdleConns < 0 || c.DBMaxIdleConns > c.DBMaxOpenConns {
+		problems = append(problems, "db_max_idle_conns must be between 0 and db_max_open_conns")
+	}
+	if c.MaxBodyBytes < 1 {
+		problems = append(problems, "max_body_bytes must be positive")
+	}
+	if !tracing.ValidExporter(c.TracingExporter) {
+		problems = append(problems, "tracing_exporter must be one of none, stdout, file, otlp")
+	}
+	if c.TracingExporter == tracing.ExporterFile && c.TracingFile == "" {
+		problems = append(problems, "tracing_file is required by the file exporter")
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		problems = append(problems, "tracing_sample_ratio must be between 0 and 1")
+	}
+	if _, err := c.CORSPolicy(); err != nil {
+		problems = append(problems, err.Error())
 	}
-	return defaultValue
+	if c.CORSMaxAge < 0 {
+		problems = append(problems, "cors_max_age must not be negative")
+	}
+	switch c.StorageBackend {
+	case "local":
+		if c.StorageDir == "" {
+			problems = append(problems, "storage_dir is required by the local backend")
+		}
+	case "s3":
+		if c.S3Endpoint == "" || c.S3Bucket == "" {
+			problems = append(problems, "s3_endpoint and s3_bucket are required by the s3 backend")
+		}
+		if !c.Debug && (c.S3AccessKey == "" || c.S3SecretKey == "") {
+			problems = append(problems, "s3_access_key and s3_secret_key are required by the s3 backend")
+		}
+	default:
+		problems = append(problems, "storage_backend must be local or s3")
+	}
+	if c.EvidenceMaxBytes < 1 {
+		problems = append(problems, "evidence_max_bytes must be positive")
+	}
+	if c.ResumableMaxBytes < 1 {
+		problems = append(problems, "resumable_max_bytes must be positive")
+	}
+	if len(c.EvidenceAllowedTypes) == 0 {
+		problems = append(problems, "evidence_allowed_types must not be empty")
+	}
+	if c.ReportDuplicateWindow < 0 || c.ReportRateWindow < 0 {
+		problems = append(problems, "report_duplicate_window and report_rate_window must not be negative")
+	}
+	if c.ReportPerTargetLimit < 0 || c.ReportRateLimit < 0 {
+		problems = append(problems, "report_per_target_limit and report_rate_limit must not be negative")
+	}
+	if _, err := c.SLATiers(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if c.DrainDelay < 0 {
+		problems = append(problems, "drain_delay must not be negative")
+	}
+	if c.SanctionCacheTTL < 0 {
+		problems = append(problems, "sanction_cache_ttl must not be negative")
+	}
+	switch c.ReportAssignment {
+	case "off", "round_robin", "least_loaded":
+	default:
+		problems = append(problems, "report_assignment must be off, round_robin or least_loaded")
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"db_conn_max_lifetime", c.DBConnMaxLifetime},
+		{"access_token_ttl", c.AccessTokenTTL},
+		{"refresh_token_ttl", c.RefreshTokenTTL},
+		{"read_timeout", c.ReadTimeout},
+		{"write_timeout", c.WriteTimeout},
+		{"idle_timeout", c.IdleTimeout},
+		{"shutdown_timeout", c.ShutdownTimeout},
+		{"evidence_url_ttl", c.EvidenceURLTTL},
+		{"resumable_upload_ttl", c.ResumableUploadTTL},
+		{"resumable_chunk_timeout", c.ResumableChunkTimeout},
+		{"report_sla_check_interval", c.ReportSLACheckInterval},
+		{"report_claim_ttl", c.ReportClaimTTL},
+		{"case_merge_window", c.CaseMergeWindow},
+		{"auto_suspension_duration", c.AutoSuspensionDuration},
+	}
+	for _, d := range durations {
+		if d.value <= 0 {
+			problems = append(problems, d.name+" must be positive")
+		}
+	}
+	if c.RefreshTokenTTL <= c.AccessTokenTTL {
+		problems = append(problems, "refresh_token_ttl must be longer than access_token_ttl")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// SLATiers parses report_sla_tiers
+func (c *Config) SLATiers() ([]models.SLATier, error) {
+	return models.ParseSLATiers(c.ReportSLATiers)
+}
+
+// TracingOptions returns the exporter settings for tracing.Setup
+func (c *Config) TracingOptions() tracing.Options {
+	return tracing.Options{
+		Exporter:    c.TracingExporter,
+		Endpoint:    c.TracingEndpoint,
+		Insecure:    c.TracingInsecure,
+		File:        c.TracingFile,
+		SampleRatio: c.TracingSampleRatio,
+	}
+}
+
+// CORSPolicy builds the cross-origin policy, including per-route overrides
+func (c *Config) CORSPolicy() (*cors.Policy, error) {
+	policy, err := cors.New(cors.Options{
+		AllowedOrigins:   c.CORSAllowedOrigins,
+		AllowedMethods:   c.CORSAllowedMethods,
+		AllowedHeaders:   c.CORSAllowedHeaders,
+		ExposedHeaders:   c.CORSExposedHeaders,
+		AllowCredentials: c.CORSAllowCredentials,
+		MaxAge:           c.CORSMaxAge,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return policy.ParseRoutes(c.CORSRouteOrigins)
+}
+
+// Print writes the effective configuration as YAML
+func (c *Config) Print(w io.Writer, redacted bool) error {
+	return sharedconfig.Print(w, c, redacted)
 }
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"fmt"
+
+	"disco/core-api/internal/config"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Connect opens a GORM connection to the Postgres database described by cfg
+func Connect(cfg *config.Config) (*gorm.DB, error) {
+	logLevel := logger.Warn
+	if cfg.Debug {
+		logLevel = logger.Info
+	}
+
+	db, err := gorm.Open(postgres.Open(DSN(cfg)), &gorm.Config{
+		Logger: newGormLogger(logLevel),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	if err := db.Use(newTracingPlugin()); err != nil {
+		return nil, fmt.Errorf("register tracing: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+
+	return db, nil
+}
+
+// DSN builds the Postgres connection string from the configuration
+func DSN(cfg *config.Config) string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=UTC",
+		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName,
+	)
+}
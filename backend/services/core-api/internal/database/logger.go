@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold is the duration above which queries are logged as warnings
+const slowQueryThreshold = 200 * time.Millisecond
+
+// gormLogger sends GORM's query log to slog, carrying the request's correlation IDs
+type gormLogger struct {
+	level logger.LogLevel
+}
+
+func newGormLogger(level logger.LogLevel) logger.Interface {
+	return &gormLogger{level: level}
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &gormLogger{level: level}
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, logger.ErrRecordNotFound):
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "query failed",
+			slog.String("sql", sql), slog.Int64("rows", rows),
+			slog.Duration("elapsed", elapsed), slog.Any("error", err))
+	case elapsed > slowQueryThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "slow query",
+			slog.String("sql", sql), slog.Int64("rows", rows),
+			slog.Duration("elapsed", elapsed))
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		slog.DebugContext(ctx, "query",
+			slog.String("sql", sql), slog.Int64("rows", rows),
+			slog.Duration("elapsed", elapsed))
+	}
+}
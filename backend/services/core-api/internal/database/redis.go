@@ -0,0 +1,26 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"disco/core-api/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ConnectRedis opens a Redis client from the configured URL and verifies it is reachable
+func ConnectRedis(ctx context.Context, cfg *config.Config) (*redis.Client, error) {
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse redis url: %w", err)
+	}
+
+	rdb := redis.NewClient(opts)
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("ping redis: %w", err)
+	}
+
+	return rdb, nil
+}
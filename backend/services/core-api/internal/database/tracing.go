@@ -0,0 +1,77 @@
+package database
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanInstanceKey = "otel:span"
+
+// tracingPlugin wraps every GORM operation in a client span that is a child of
+// the span in the statement's context, so queries show up under their request
+type tracingPlugin struct {
+	tracer trace.Tracer
+}
+
+func newTracingPlugin() *tracingPlugin {
+	return &tracingPlugin{tracer: otel.Tracer("disco/core-api/internal/database")}
+}
+
+func (p *tracingPlugin) Name() string {
+	return "otel-tracing"
+}
+
+func (p *tracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	registrations := []error{
+		cb.Create().Before("gorm:create").Register("otel:before_create", p.before("gorm.create")),
+		cb.Create().After("gorm:create").Register("otel:after_create", p.after),
+		cb.Query().Before("gorm:query").Register("otel:before_query", p.before("gorm.query")),
+		cb.Query().After("gorm:query").Register("otel:after_query", p.after),
+		cb.Update().Before("gorm:update").Register("otel:before_update", p.before("gorm.update")),
+		cb.Update().After("gorm:update").Register("otel:after_update", p.after),
+		cb.Delete().Before("gorm:delete").Register("otel:before_delete", p.before("gorm.delete")),
+		cb.Delete().After("gorm:delete").Register("otel:after_delete", p.after),
+		cb.Row().Before("gorm:row").Register("otel:before_row", p.before("gorm.row")),
+		cb.Row().After("gorm:row").Register("otel:after_row", p.after),
+		cb.Raw().Before("gorm:raw").Register("otel:before_raw", p.before("gorm.raw")),
+		cb.Raw().After("gorm:raw").Register("otel:after_raw", p.after),
+	}
+	return errors.Join(registrations...)
+}
+
+func (p *tracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := p.tracer.Start(db.Statement.Context, operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(spanInstanceKey, span)
+	}
+}
+
+func (p *tracingPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanInstanceKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBSQLTable(db.Statement.Table),
+		semconv.DBStatement(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
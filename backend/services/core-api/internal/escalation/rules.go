@@ -0,0 +1,148 @@
+// Package escalation loads the declarative rules that escalate safety
+// reports automatically when several reports about one user add up
+package escalation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"disco/core-api/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is the layout of the rules file
+type File struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule fires its actions when the reports about a user within Match.Window
+// meet every threshold in Match
+type Rule struct {
+	Name    string  `yaml:"name"`
+	Match   Match   `yaml:"match"`
+	Actions Actions `yaml:"actions"`
+}
+
+// Match selects the reports a rule counts. Reports of other types, and
+// reports from reporters below MinCredibility, are ignored.
+type Match struct {
+	Types                []models.IncidentType `yaml:"types"`
+	Window               time.Duration         `yaml:"window"`
+	MinReports           int                   `yaml:"min_reports"`
+	MinDistinctReporters int                   `yaml:"min_distinct_reporters"`
+	MinCredibility       float64               `yaml:"min_credibility"`
+}
+
+// Actions are applied once per rule and reported user within the window
+type Actions struct {
+	// RaisePriority lifts the user's open reports to at least this priority,
+	// capped below emergency priority, and moves them to that priority's SLA
+	RaisePriority int `yaml:"raise_priority"`
+	// Suspend suspends the reported user for auto_suspension_duration,
+	// pending a moderator's review
+	Suspend bool `yaml:"suspend"`
+	// PageModerators notifies moderators connected to the websocket hub
+	PageModerators bool `yaml:"page_moderators"`
+}
+
+// Stats summarises the reports about one user that a rule counts
+type Stats struct {
+	Reports           int
+	DistinctReporters int
+}
+
+// AppliesTo reports whether reports of type t are counted by the rule
+func (r *Rule) AppliesTo(t models.IncidentType) bool {
+	if len(r.Match.Types) == 0 {
+		return true
+	}
+	for _, mt := range r.Match.Types {
+		if mt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether stats meet the rule's thresholds
+func (r *Rule) Matches(stats Stats) bool {
+	return stats.Reports >= r.Match.MinReports &&
+		stats.DistinctReporters >= r.Match.MinDistinctReporters
+}
+
+// Validate checks that the rule can fire and does something when it does
+func (r *Rule) Validate() error {
+	var problems []string
+	if r.Name == "" {
+		problems = append(problems, "name is required")
+	}
+	if r.Match.Window <= 0 {
+		problems = append(problems, "match.window must be positive")
+	}
+	if r.Match.MinReports < 0 || r.Match.MinDistinctReporters < 0 {
+		problems = append(problems, "match thresholds must not be negative")
+	}
+	if r.Match.MinReports == 0 && r.Match.MinDistinctReporters == 0 {
+		problems = append(problems, "set match.min_reports or match.min_distinct_reporters")
+	}
+	if r.Match.MinCredibility < 0 || r.Match.MinCredibility > 1 {
+		problems = append(problems, "match.min_credibility must be between 0 and 1")
+	}
+	for _, t := range r.Match.Types {
+		if !t.Valid() {
+			problems = append(problems, fmt.Sprintf("unknown incident type %q", t))
+		}
+	}
+	if r.Actions.RaisePriority < 0 {
+		problems = append(problems, "actions.raise_priority must not be negative")
+	}
+	if r.Actions.RaisePriority == 0 && !r.Actions.Suspend && !r.Actions.PageModerators {
+		problems = append(problems, "at least one action is required")
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Parse decodes and validates a rules file. Unknown keys are rejected so a
+// misspelt threshold cannot silently disable a rule.
+func Parse(data []byte) ([]Rule, error) {
+	var file File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse escalation rules: %w", err)
+	}
+
+	seen := make(map[string]bool, len(file.Rules))
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("escalation rule %d (%s): %w", i+1, rule.Name, err)
+		}
+		if seen[rule.Name] {
+			return nil, fmt.Errorf("escalation rule %q is defined twice", rule.Name)
+		}
+		seen[rule.Name] = true
+	}
+	return file.Rules, nil
+}
+
+// Load reads the rules file at path. An empty path means no rules.
+func Load(path string) ([]Rule, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read escalation rules: %w", err)
+	}
+	return Parse(data)
+}
@@ -0,0 +1,209 @@
+package escalation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"disco/core-api/internal/models"
+)
+
+func validRule() Rule {
+	return Rule{
+		Name: "repeat-harassment",
+		Match: Match{
+			Types:                []models.IncidentType{models.IncidentTypeHarassment},
+			Window:               24 * time.Hour,
+			MinReports:           3,
+			MinDistinctReporters: 2,
+			MinCredibility:       0.4,
+		},
+		Actions: Actions{RaisePriority: 80, PageModerators: true},
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(r *Rule)
+		// wantErrs lists substrings of the expected error; none means valid
+		wantErrs []string
+	}{
+		{name: "valid", modify: func(r *Rule) {}},
+		{name: "any incident type", modify: func(r *Rule) { r.Match.Types = nil }},
+		{name: "distinct reporters only", modify: func(r *Rule) { r.Match.MinReports = 0 }},
+		{name: "suspend only", modify: func(r *Rule) { r.Actions = Actions{Suspend: true} }},
+		{name: "credibility bounds are inclusive", modify: func(r *Rule) { r.Match.MinCredibility = 1 }},
+		{name: "missing name", modify: func(r *Rule) { r.Name = "" }, wantErrs: []string{"name is required"}},
+		{name: "zero window", modify: func(r *Rule) { r.Match.Window = 0 }, wantErrs: []string{"match.window must be positive"}},
+		{name: "negative window", modify: func(r *Rule) { r.Match.Window = -time.Hour }, wantErrs: []string{"match.window must be positive"}},
+		{name: "negative threshold", modify: func(r *Rule) { r.Match.MinReports = -1 }, wantErrs: []string{"must not be negative"}},
+		{
+			name:     "no thresholds",
+			modify:   func(r *Rule) { r.Match.MinReports, r.Match.MinDistinctReporters = 0, 0 },
+			wantErrs: []string{"set match.min_reports or match.min_distinct_reporters"},
+		},
+		{name: "credibility above one", modify: func(r *Rule) { r.Match.MinCredibility = 1.5 }, wantErrs: []string{"between 0 and 1"}},
+		{name: "negative credibility", modify: func(r *Rule) { r.Match.MinCredibility = -0.1 }, wantErrs: []string{"between 0 and 1"}},
+		{
+			name:     "unknown incident type",
+			modify:   func(r *Rule) { r.Match.Types = append(r.Match.Types, "spam") },
+			wantErrs: []string{`unknown incident type "spam"`},
+		},
+		{name: "negative priority", modify: func(r *Rule) { r.Actions.RaisePriority = -5 }, wantErrs: []string{"actions.raise_priority must not be negative"}},
+		{name: "no actions", modify: func(r *Rule) { r.Actions = Actions{} }, wantErrs: []string{"at least one action is required"}},
+		{
+			name: "every problem is reported",
+			modify: func(r *Rule) {
+				*r = Rule{Match: Match{MinCredibility: 2}}
+			},
+			wantErrs: []string{
+				"name is required",
+				"match.window must be positive",
+				"set match.min_reports",
+				"between 0 and 1",
+				"at least one action is required",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := validRule()
+			tt.modify(&rule)
+
+			err := rule.Validate()
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Validate() = nil, want an error")
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr string
+	}{
+		{name: "empty file", data: "", want: 0},
+		{
+			name: "rules",
+			data: `
+rules:
+  - name: repeat-harassment
+    match:
+      types: [harassment]
+      window: 24h
+      min_reports: 3
+    actions:
+      raise_priority: 80
+  - name: many-reporters
+    match:
+      window: 72h
+      min_distinct_reporters: 5
+    actions:
+      suspend: true
+      page_moderators: true
+`,
+			want: 2,
+		},
+		{
+			name: "misspelt threshold",
+			data: `
+rules:
+  - name: typo
+    match:
+      window: 24h
+      min_report: 3
+    actions:
+      suspend: true
+`,
+			wantErr: "min_report",
+		},
+		{
+			name: "invalid rule",
+			data: `
+rules:
+  - name: no-actions
+    match:
+      window: 24h
+      min_reports: 3
+`,
+			wantErr: "escalation rule 1 (no-actions): at least one action is required",
+		},
+		{
+			name: "duplicate name",
+			data: `
+rules:
+  - name: dup
+    match: {window: 1h, min_reports: 2}
+    actions: {suspend: true}
+  - name: dup
+    match: {window: 2h, min_reports: 3}
+    actions: {page_moderators: true}
+`,
+			wantErr: `escalation rule "dup" is defined twice`,
+		},
+		{
+			name:    "invalid duration",
+			data:    "rules:\n  - name: bad\n    match: {window: soon, min_reports: 2}\n    actions: {suspend: true}\n",
+			wantErr: "parse escalation rules",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := Parse([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(rules) != tt.want {
+				t.Fatalf("Parse() returned %d rules, want %d", len(rules), tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleMatching(t *testing.T) {
+	rule := validRule()
+
+	if !rule.AppliesTo(models.IncidentTypeHarassment) || rule.AppliesTo(models.IncidentTypeImpersonation) {
+		t.Error("AppliesTo() does not follow match.types")
+	}
+	rule.Match.Types = nil
+	if !rule.AppliesTo(models.IncidentTypeImpersonation) {
+		t.Error("AppliesTo() with no types should count every report")
+	}
+
+	tests := []struct {
+		stats Stats
+		want  bool
+	}{
+		{Stats{Reports: 3, DistinctReporters: 2}, true},
+		{Stats{Reports: 10, DistinctReporters: 5}, true},
+		{Stats{Reports: 2, DistinctReporters: 2}, false},
+		{Stats{Reports: 3, DistinctReporters: 1}, false},
+	}
+	for _, tt := range tests {
+		if got := rule.Matches(tt.stats); got != tt.want {
+			t.Errorf("Matches(%+v) = %v, want %v", tt.stats, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package export writes and verifies the signed record bundles handed to
+// law enforcement. A bundle is a zip archive whose manifest lists the SHA-256
+// of every other file, with a detached Ed25519ph signature over the archive.
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Format identifies the bundle layout in its manifest
+const Format = "disco-export/1"
+
+// ManifestPath is where the manifest sits in the archive
+const ManifestPath = "manifest.json"
+
+// Subject kinds
+const (
+	SubjectReport = "report"
+	SubjectAlert  = "alert"
+)
+
+// ErrInvalidBundle is returned when a bundle fails verification
+var ErrInvalidBundle = errors.New("invalid export bundle")
+
+// Subject is the report or alert a bundle was produced for
+type Subject struct {
+	Kind string    `json:"kind"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// File is a manifest entry for one file in the archive
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// EvidenceID links an evidence file back to its evidence record
+	EvidenceID *uuid.UUID `json:"evidence_id,omitempty"`
+}
+
+// Manifest describes a bundle and pins the hash of every file in it
+type Manifest struct {
+	Format      string    `json:"format"`
+	Subject     Subject   `json:"subject"`
+	Reference   string    `json:"reference"`
+	GeneratedAt time.Time `json:"generated_at"`
+	GeneratedBy uuid.UUID `json:"generated_by"`
+	// KeyID is the fingerprint of the key that signs the bundle
+	KeyID string `json:"key_id"`
+	Files []File `json:"files"`
+	// Warnings records problems found while collecting the records, such as
+	// an evidence file that no longer matches its recorded hash
+	Warnings []string `json:"warnings"`
+}
+
+// Writer adds files to a bundle and records them in its manifest
+type Writer struct {
+	zw       *zip.Writer
+	manifest *Manifest
+}
+
+// NewWriter starts a bundle on w. Close writes the manifest.
+func NewWriter(w io.Writer, manifest *Manifest) *Writer {
+	manifest.Format = Format
+	if manifest.Files == nil {
+		manifest.Files = []File{}
+	}
+	if manifest.Warnings == nil {
+		manifest.Warnings = []string{}
+	}
+	return &Writer{zw: zip.NewWriter(w), manifest: manifest}
+}
+
+// Warn records a problem in the manifest
+func (w *Writer) Warn(format string, args ...interface{}) {
+	w.manifest.Warnings = append(w.manifest.Warnings, fmt.Sprintf(format, args...))
+}
+
+// AddJSON writes v as indented JSON at name
+func (w *Writer) AddJSON(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.add(name, nil, bytes.NewReader(data))
+	return err
+}
+
+// AddFile copies r into the archive at name and returns its SHA-256
+func (w *Writer) AddFile(name string, evidenceID *uuid.UUID, r io.Reader) (string, error) {
+	return w.add(name, evidenceID, r)
+}
+
+func (w *Writer) add(name string, evidenceID *uuid.UUID, r io.Reader) (string, error) {
+	if name == ManifestPath || !validPath(name) {
+		return "", fmt.Errorf("invalid bundle path %q", name)
+	}
+	f, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: w.manifest.GeneratedAt,
+	})
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), r)
+	if err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	w.manifest.Files = append(w.manifest.Files, File{Path: name, Size: n, SHA256: sum, EvidenceID: evidenceID})
+	return sum, nil
+}
+
+// Close writes the manifest and finishes the archive
+func (w *Writer) Close() error {
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     ManifestPath,
+		Method:   zip.Deflate,
+		Modified: w.manifest.GeneratedAt,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+// VerifyContents checks that the archive holds exactly the files its
+// manifest lists, with matching sizes and hashes, and returns the manifest
+func VerifyContents(r io.ReaderAt, size int64) (*Manifest, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		if _, dup := files[f.Name]; dup {
+			return nil, fmt.Errorf("%w: %s appears twice", ErrInvalidBundle, f.Name)
+		}
+		files[f.Name] = f
+	}
+
+	mf, ok := files[ManifestPath]
+	if !ok {
+		return nil, fmt.Errorf("%w: no manifest", ErrInvalidBundle)
+	}
+	var manifest Manifest
+	if err := readJSON(mf, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: manifest: %v", ErrInvalidBundle, err)
+	}
+	if manifest.Format != Format {
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidBundle, manifest.Format)
+	}
+
+	var problems []string
+	listed := make(map[string]bool, len(manifest.Files))
+	for _, entry := range manifest.Files {
+		listed[entry.Path] = true
+		f, ok := files[entry.Path]
+		if !ok {
+			problems = append(problems, entry.Path+" is missing")
+			continue
+		}
+		sum, n, err := hashZipFile(f)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", entry.Path, err))
+			continue
+		}
+		if n != entry.Size || sum != entry.SHA256 {
+			problems = append(problems, entry.Path+" does not match its manifest hash")
+		}
+	}
+	var extra []string
+	for name := range files {
+		if name != ManifestPath && !listed[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		problems = append(problems, name+" is not listed in the manifest")
+	}
+
+	if len(problems) > 0 {
+		return &manifest, fmt.Errorf("%w: %v", ErrInvalidBundle, problems)
+	}
+	return &manifest, nil
+}
+
+func hashZipFile(f *zip.File) (string, int64, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", 0, err
+	}
+	defer rc.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, rc)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+func readJSON(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
+
+// validPath accepts clean relative slash-separated paths only
+func validPath(name string) bool {
+	return name != "" && name != "." && path.Clean(name) == name &&
+		!strings.HasPrefix(name, "/") && name != ".." && !strings.HasPrefix(name, "../")
+}
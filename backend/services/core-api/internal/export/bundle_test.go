@@ -0,0 +1,208 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var generatedAt = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// writeBundle builds a small report bundle, returning the archive and its digest
+func writeBundle(t *testing.T) ([]byte, *Digest) {
+	t.Helper()
+	var buf bytes.Buffer
+	digest := NewDigest()
+	w := NewWriter(io.MultiWriter(&buf, digest), &Manifest{
+		Subject:     Subject{Kind: SubjectReport, ID: uuid.New()},
+		Reference:   "LE-2026-0042",
+		GeneratedAt: generatedAt,
+		GeneratedBy: uuid.New(),
+		KeyID:       "0123456789abcdef",
+	})
+	if err := w.AddJSON("report.json", map[string]string{"description": "sent threatening messages"}); err != nil {
+		t.Fatalf("AddJSON: %v", err)
+	}
+	evidenceID := uuid.New()
+	if _, err := w.AddFile("evidence/photo.jpg", &evidenceID, strings.NewReader("\xff\xd8\xff\xe0 not really a jpeg")); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	w.Warn("evidence %s no longer matches its recorded hash", "clip.mp4")
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes(), digest
+}
+
+// rewrite copies a bundle's files into a new archive, letting edit change,
+// drop (nil) or add entries
+func rewrite(t *testing.T, archive []byte, edit func(files map[string][]byte)) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("read bundle: %v", err)
+	}
+	var names []string
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		names = append(names, f.Name)
+		files[f.Name] = data
+	}
+	edit(files)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string) {
+		data, ok := files[name]
+		if !ok || data == nil {
+			return
+		}
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := f.Write(data); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	for _, name := range names {
+		write(name)
+		delete(files, name)
+	}
+	for name := range files {
+		write(name)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close bundle: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestVerifyContents(t *testing.T) {
+	archive, _ := writeBundle(t)
+
+	manifest, err := VerifyContents(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("VerifyContents() = %v", err)
+	}
+	if manifest.Format != Format || manifest.Reference != "LE-2026-0042" || !manifest.GeneratedAt.Equal(generatedAt) {
+		t.Errorf("manifest = %+v, want the one written", manifest)
+	}
+	if len(manifest.Files) != 2 || manifest.Files[0].Path != "report.json" || manifest.Files[1].EvidenceID == nil {
+		t.Errorf("manifest files = %+v, want report.json and the evidence file", manifest.Files)
+	}
+	if len(manifest.Warnings) != 1 {
+		t.Errorf("manifest warnings = %v, want the one recorded", manifest.Warnings)
+	}
+
+	tests := []struct {
+		name string
+		edit func(files map[string][]byte)
+		want string
+	}{
+		{
+			name: "file changed by one byte",
+			edit: func(files map[string][]byte) { files["evidence/photo.jpg"][4] ^= 0x01 },
+			want: "evidence/photo.jpg does not match its manifest hash",
+		},
+		{
+			name: "manifest hash changed by one byte",
+			edit: func(files map[string][]byte) {
+				m := files[ManifestPath]
+				i := bytes.Index(m, []byte(`"sha256": "`)) + len(`"sha256": "`)
+				if m[i] == '0' {
+					m[i] = '1'
+				} else {
+					m[i] = '0'
+				}
+			},
+			want: "report.json does not match its manifest hash",
+		},
+		{
+			name: "file removed",
+			edit: func(files map[string][]byte) { files["report.json"] = nil },
+			want: "report.json is missing",
+		},
+		{
+			name: "file added",
+			edit: func(files map[string][]byte) { files["evidence/extra.jpg"] = []byte("planted") },
+			want: "evidence/extra.jpg is not listed in the manifest",
+		},
+		{
+			name: "manifest removed",
+			edit: func(files map[string][]byte) { files[ManifestPath] = nil },
+			want: "no manifest",
+		},
+		{
+			name: "manifest not JSON",
+			edit: func(files map[string][]byte) { files[ManifestPath] = []byte("{") },
+			want: "manifest:",
+		},
+		{
+			name: "unknown format",
+			edit: func(files map[string][]byte) {
+				files[ManifestPath] = bytes.Replace(files[ManifestPath], []byte(Format), []byte("disco-export/9"), 1)
+			},
+			want: `unsupported format "disco-export/9"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := rewrite(t, archive, tt.edit)
+			_, err := VerifyContents(bytes.NewReader(tampered), int64(len(tampered)))
+			if !errors.Is(err, ErrInvalidBundle) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("VerifyContents() = %v, want %v mentioning %q", err, ErrInvalidBundle, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyContentsRejectsDuplicates(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"report.json", "report.json", ManifestPath} {
+		if _, err := zw.Create(name); err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	_, err := VerifyContents(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if !errors.Is(err, ErrInvalidBundle) || !strings.Contains(err.Error(), "report.json appears twice") {
+		t.Errorf("VerifyContents() = %v, want a duplicate entry error", err)
+	}
+
+	garbage := []byte("not a zip archive")
+	if _, err := VerifyContents(bytes.NewReader(garbage), int64(len(garbage))); !errors.Is(err, ErrInvalidBundle) {
+		t.Errorf("VerifyContents(garbage) = %v, want %v", err, ErrInvalidBundle)
+	}
+}
+
+func TestWriterRejectsBadPaths(t *testing.T) {
+	w := NewWriter(io.Discard, &Manifest{})
+	for _, name := range []string{"", ".", "..", "../report.json", "/etc/passwd", "evidence//photo.jpg", "evidence/../report.json", ManifestPath} {
+		if _, err := w.AddFile(name, nil, strings.NewReader("x")); err == nil {
+			t.Errorf("AddFile(%q) succeeded, want an error", name)
+		}
+	}
+	if _, err := w.AddFile("evidence/photo.jpg", nil, strings.NewReader("x")); err != nil {
+		t.Errorf("AddFile(evidence/photo.jpg) = %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package export
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrBadSignature is returned when a detached signature does not match
+var ErrBadSignature = errors.New("bundle signature does not match")
+
+// ed25519ph signs the SHA-512 digest of the archive so bundles holding large
+// evidence files can be signed and verified without reading them into memory
+var signOptions = &ed25519.Options{Hash: crypto.SHA512}
+
+// Digest hashes an archive as it is written, for Sign
+type Digest struct {
+	hash.Hash
+}
+
+// NewDigest returns an empty archive digest
+func NewDigest() *Digest {
+	return &Digest{Hash: sha512.New()}
+}
+
+// Sign returns the detached signature for an archive digest: the base64
+// Ed25519ph signature on a single line
+func Sign(key ed25519.PrivateKey, digest *Digest) (string, error) {
+	sig, err := key.Sign(nil, digest.Sum(nil), signOptions)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// Verify checks a detached signature against the archive read from r
+func Verify(pub ed25519.PublicKey, r io.Reader, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return fmt.Errorf("%w: signature is not base64", ErrBadSignature)
+	}
+	digest := NewDigest()
+	if _, err := io.Copy(digest, r); err != nil {
+		return err
+	}
+	if err := ed25519.VerifyWithOptions(pub, digest.Sum(nil), sig, signOptions); err != nil {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// KeyID returns a short fingerprint of a public key
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// LoadPrivateKey reads a PKCS #8 PEM Ed25519 key, such as one made by
+// `openssl genpkey -algorithm ed25519`. An empty path means no key.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	if path == "" {
+		return nil, nil
+	}
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse export signing key: %w", err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("export signing key is not an Ed25519 key")
+	}
+	return priv, nil
+}
+
+// LoadPublicKey reads a PKIX PEM Ed25519 public key
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse export public key: %w", err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("export public key is not an Ed25519 key")
+	}
+	return pub, nil
+}
+
+// EncodePublicKey returns pub as a PKIX PEM block for recipients
+func EncodePublicKey(pub ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM block found", path)
+	}
+	return block, nil
+}
@@ -0,0 +1,189 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+func TestSignVerify(t *testing.T) {
+	key := newKey(t)
+	pub := key.Public().(ed25519.PublicKey)
+	archive, digest := writeBundle(t)
+
+	signature, err := Sign(key, digest)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if err := Verify(pub, bytes.NewReader(archive), signature); err != nil {
+		t.Fatalf("Verify() = %v", err)
+	}
+	if err := Verify(pub, bytes.NewReader(archive), signature+"\n"); err != nil {
+		t.Errorf("Verify() with trailing newline = %v", err)
+	}
+
+	dataAt := dataOffsets(t, archive)
+	flip := func(i int) []byte {
+		tampered := bytes.Clone(archive)
+		tampered[i] ^= 0x01
+		return tampered
+	}
+	sig, _ := base64.StdEncoding.DecodeString(signature)
+	sig[0] ^= 0x01
+	otherArchive, otherDigest := writeBundle(t)
+	otherSignature, err := Sign(key, otherDigest)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		pub       ed25519.PublicKey
+		archive   []byte
+		signature string
+	}{
+		{"first byte changed", pub, flip(0), signature},
+		{"evidence byte changed", pub, flip(dataAt["evidence/photo.jpg"]), signature},
+		{"manifest byte changed", pub, flip(dataAt[ManifestPath]), signature},
+		{"last byte changed", pub, flip(len(archive) - 1), signature},
+		{"truncated", pub, archive[:len(archive)-1], signature},
+		{"extended", pub, append(bytes.Clone(archive), 0), signature},
+		{"signature changed", pub, archive, base64.StdEncoding.EncodeToString(sig)},
+		{"signature for another bundle", pub, archive, otherSignature},
+		{"bundle for another signature", pub, otherArchive, signature},
+		{"another key", newKey(t).Public().(ed25519.PublicKey), archive, signature},
+		{"signature not base64", pub, archive, "not base64!"},
+		{"no signature", pub, archive, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.pub, bytes.NewReader(tt.archive), tt.signature); !errors.Is(err, ErrBadSignature) {
+				t.Errorf("Verify() = %v, want %v", err, ErrBadSignature)
+			}
+		})
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	dir := t.TempDir()
+	key := newKey(t)
+	pub := key.Public().(ed25519.PublicKey)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal private key: %v", err)
+	}
+	privPath := writePEM(t, dir, "signing.pem", "PRIVATE KEY", der)
+	pubPEM, err := EncodePublicKey(pub)
+	if err != nil {
+		t.Fatalf("EncodePublicKey: %v", err)
+	}
+	pubPath := filepath.Join(dir, "public.pem")
+	if err := os.WriteFile(pubPath, pubPEM, 0o600); err != nil {
+		t.Fatalf("write public key: %v", err)
+	}
+
+	loaded, err := LoadPrivateKey(privPath)
+	if err != nil || !loaded.Equal(key) {
+		t.Fatalf("LoadPrivateKey() = %v, want the written key", err)
+	}
+	loadedPub, err := LoadPublicKey(pubPath)
+	if err != nil || !loadedPub.Equal(pub) {
+		t.Fatalf("LoadPublicKey() = %v, want the written key", err)
+	}
+	if KeyID(loadedPub) != KeyID(pub) || len(KeyID(pub)) != 16 {
+		t.Errorf("KeyID() = %q, want a stable 16 character fingerprint", KeyID(loadedPub))
+	}
+
+	if key, err := LoadPrivateKey(""); key != nil || err != nil {
+		t.Errorf("LoadPrivateKey(\"\") = %v, %v, want no key", key, err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ecdsa key: %v", err)
+	}
+	ecDER, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("marshal ecdsa key: %v", err)
+	}
+	ecPubDER, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal ecdsa public key: %v", err)
+	}
+	notPEM := filepath.Join(dir, "key.txt")
+	if err := os.WriteFile(notPEM, []byte("not a key"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	failures := []struct {
+		name string
+		load func() error
+	}{
+		{"ecdsa private key", func() error {
+			_, err := LoadPrivateKey(writePEM(t, dir, "ec.pem", "PRIVATE KEY", ecDER))
+			return err
+		}},
+		{"ecdsa public key", func() error {
+			_, err := LoadPublicKey(writePEM(t, dir, "ec.pub", "PUBLIC KEY", ecPubDER))
+			return err
+		}},
+		{"public key as private", func() error { _, err := LoadPrivateKey(pubPath); return err }},
+		{"private key as public", func() error { _, err := LoadPublicKey(privPath); return err }},
+		{"not PEM", func() error { _, err := LoadPrivateKey(notPEM); return err }},
+		{"missing file", func() error { _, err := LoadPublicKey(filepath.Join(dir, "missing.pem")); return err }},
+	}
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.load(); err == nil {
+				t.Error("load succeeded, want an error")
+			}
+		})
+	}
+}
+
+// dataOffsets returns where each file's compressed data starts in archive
+func dataOffsets(t *testing.T, archive []byte) map[string]int {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("read bundle: %v", err)
+	}
+	offsets := make(map[string]int, len(zr.File))
+	for _, f := range zr.File {
+		at, err := f.DataOffset()
+		if err != nil {
+			t.Fatalf("data offset of %s: %v", f.Name, err)
+		}
+		offsets[f.Name] = int(at)
+	}
+	return offsets
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return p
+}
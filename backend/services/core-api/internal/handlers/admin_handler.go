@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/middleware"
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AdminHandler handles administrative HTTP requests
+type AdminHandler struct {
+	userService *services.UserService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(userService *services.UserService) *AdminHandler {
+	return &AdminHandler{
+		userService: userService,
+	}
+}
+
+// RegisterRoutes registers the admin routes
+func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := router.Group("/admin")
+	admin.Use(middleware.Require(auth.PermUsersManage))
+	{
+		admin.PUT("/users/:id/role", h.updateUserRole)
+	}
+}
+
+// updateUserRole assigns a new role to a user
+func (h *AdminHandler) updateUserRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req struct {
+		Role models.Role `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userService.UpdateRole(c.Request.Context(), userID, req.Role); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRole):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User role updated"})
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/middleware"
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Appeal routes locked-out users need; the sanctions middleware exempts them
+const (
+	FileAppealRoute = "/api/v1/safety/sanctions/:id/appeal"
+	MyAppealsRoute  = "/api/v1/safety/appeals/mine"
+)
+
+// AppealHandler handles sanction appeal HTTP requests
+type AppealHandler struct {
+	appealService *services.AppealService
+}
+
+// NewAppealHandler creates a new appeal handler
+func NewAppealHandler(appealService *services.AppealService) *AppealHandler {
+	return &AppealHandler{
+		appealService: appealService,
+	}
+}
+
+// RegisterRoutes registers the appeal routes
+func (h *AppealHandler) RegisterRoutes(router *gin.RouterGroup) {
+	safety := router.Group("/safety")
+	{
+		safety.POST("/sanctions/:id/appeal", h.fileAppeal)
+		safety.GET("/appeals/mine", h.listMyAppeals)
+		safety.GET("/appeals", middleware.Require(auth.PermReportsModerate), h.listAppealQueue)
+		safety.POST("/appeals/:id/decision", middleware.Require(auth.PermReportsModerate), h.decideAppeal)
+	}
+}
+
+// fileAppeal contests one of the caller's sanctions
+func (h *AppealHandler) fileAppeal(c *gin.Context) {
+	sanctionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sanction ID"})
+		return
+	}
+
+	var req struct {
+		Statement string `json:"statement" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	appeal, err := h.appealService.FileAppeal(c.Request.Context(), userID.(uuid.UUID), sanctionID, req.Statement)
+	if err != nil {
+		respondAppealError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, appeal)
+}
+
+// listMyAppeals returns the caller's appeals and their outcomes
+func (h *AppealHandler) listMyAppeals(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	appeals, err := h.appealService.ListMyAppeals(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, appeals)
+}
+
+// listAppealQueue returns the pending appeals the caller may decide
+func (h *AppealHandler) listAppealQueue(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	appeals, err := h.appealService.ListAppealQueue(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, appeals)
+}
+
+// decideAppeal records a second reviewer's outcome for an appeal
+func (h *AppealHandler) decideAppeal(c *gin.Context) {
+	appealID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appeal ID"})
+		return
+	}
+
+	var req struct {
+		Outcome models.AppealStatus `json:"outcome" binding:"required"`
+		Note    string              `json:"note" binding:"required"`
+		// Duration is the reduced sanction's length from when it was issued
+		Duration string `json:"duration"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	duration, err := parseOptionalDuration(req.Duration)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	appeal, err := h.appealService.DecideAppeal(c.Request.Context(), appealID, userID.(uuid.UUID), services.AppealDecision{
+		Outcome:  req.Outcome,
+		Note:     req.Note,
+		Duration: duration,
+	})
+	if err != nil {
+		respondAppealError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
+
+// respondAppealError maps appeal errors to HTTP status codes
+func respondAppealError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAppealNotFound), errors.Is(err, services.ErrSanctionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAppealReviewer):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAppealExists), errors.Is(err, services.ErrAppealDecided),
+		errors.Is(err, services.ErrSanctionNotInForce):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidAppealDecision), errors.Is(err, services.ErrNoteRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogoutRoute stays open to locked-out users; the sanctions middleware exempts it
+const LogoutRoute = "/api/v1/auth/logout"
+
+// AuthHandler handles authentication HTTP requests
+type AuthHandler struct {
+	authService *services.AuthService
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(authService *services.AuthService) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+	}
+}
+
+// RegisterRoutes registers the public auth routes
+func (h *AuthHandler) RegisterRoutes(router *gin.RouterGroup) {
+	authGroup := router.Group("/auth")
+	{
+		authGroup.POST("/login", h.login)
+		authGroup.POST("/refresh", h.refresh)
+	}
+}
+
+// RegisterProtectedRoutes registers the auth routes that require a valid access token
+func (h *AuthHandler) RegisterProtectedRoutes(router *gin.RouterGroup) {
+	router.POST("/auth/logout", h.logout)
+}
+
+// login exchanges credentials for a token pair. Locked-out users get an
+// appeal-scoped token alongside the 403 instead.
+func (h *AuthHandler) login(c *gin.Context) {
+	var req struct {
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pair, user, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		var locked *services.AccountLockedError
+		if errors.As(err, &locked) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "tokens": locked.AppealToken})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": pair, "user": user})
+}
+
+// refresh rotates a refresh token
+func (h *AuthHandler) refresh(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pair, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrTokenRevoked) || errors.Is(err, auth.ErrTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		var locked *services.AccountLockedError
+		if errors.As(err, &locked) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "tokens": locked.AppealToken})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
+}
+
+// logout revokes the caller's access token and refresh token family
+func (h *AuthHandler) logout(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), claims.(*auth.Claims)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/middleware"
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CaseHandler handles investigation case HTTP requests
+type CaseHandler struct {
+	caseService *services.CaseService
+}
+
+// NewCaseHandler creates a new case handler
+func NewCaseHandler(caseService *services.CaseService) *CaseHandler {
+	return &CaseHandler{
+		caseService: caseService,
+	}
+}
+
+// RegisterRoutes registers the case routes
+func (h *CaseHandler) RegisterRoutes(router *gin.RouterGroup) {
+	safety := router.Group("/safety")
+	{
+		safety.GET("/cases", middleware.Require(auth.PermReportsRead), h.listCases)
+		safety.POST("/cases", middleware.Require(auth.PermReportsModerate), h.createCase)
+		safety.GET("/cases/:id", middleware.Require(auth.PermReportsRead), h.getCase)
+		safety.PATCH("/cases/:id", middleware.Require(auth.PermReportsModerate), h.updateCase)
+		safety.GET("/cases/:id/timeline", middleware.Require(auth.PermReportsRead), h.getTimeline)
+		safety.GET("/cases/:id/suggestions", middleware.Require(auth.PermReportsRead), h.suggestForCase)
+		safety.POST("/cases/:id/links", middleware.Require(auth.PermReportsModerate), h.linkRecord)
+		safety.DELETE("/cases/:id/links/:linkID", middleware.Require(auth.PermReportsModerate), h.unlinkRecord)
+		safety.GET("/cases/:id/notes", middleware.Require(auth.PermReportsRead), h.listNotes)
+		safety.POST("/cases/:id/notes", middleware.Require(auth.PermReportsModerate), h.addNote)
+		safety.GET("/reports/:id/merge-candidates", middleware.Require(auth.PermReportsRead), h.suggestForReport)
+	}
+}
+
+// listCases returns cases, optionally filtered by status, owner or subject
+func (h *CaseHandler) listCases(c *gin.Context) {
+	var filter services.CaseFilter
+	if v := c.Query("status"); v != "" {
+		status := models.CaseStatus(v)
+		if !status.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrInvalidCaseStatus.Error()})
+			return
+		}
+		filter.Status = &status
+	}
+	for param, dst := range map[string]**uuid.UUID{
+		"owner_id":   &filter.OwnerID,
+		"subject_id": &filter.SubjectID,
+	} {
+		if v := c.Query(param); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+				return
+			}
+			*dst = &id
+		}
+	}
+
+	cases, err := h.caseService.ListCases(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cases)
+}
+
+// createCase opens a case, optionally with some reports already linked
+func (h *CaseHandler) createCase(c *gin.Context) {
+	var req struct {
+		Title     string      `json:"title" binding:"required"`
+		SubjectID *uuid.UUID  `json:"subject_id"`
+		ReportIDs []uuid.UUID `json:"report_ids"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	created, err := h.caseService.CreateCase(c.Request.Context(), userID.(uuid.UUID), req.Title, req.SubjectID, req.ReportIDs)
+	if err != nil {
+		respondCaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// getCase returns a case with its linked records and notes
+func (h *CaseHandler) getCase(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid case ID"})
+		return
+	}
+
+	detail, err := h.caseService.GetCase(c.Request.Context(), caseID)
+	if err != nil {
+		respondCaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// updateCase changes a case's title, status or owner. An empty owner_id
+// unassigns the case.
+func (h *CaseHandler) updateCase(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid case ID"})
+		return
+	}
+
+	var req struct {
+		Title   *string            `json:"title"`
+		Status  *models.CaseStatus `json:"status"`
+		OwnerID *string            `json:"owner_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	update := services.CaseUpdate{Title: req.Title, Status: req.Status}
+	if req.OwnerID != nil {
+		if *req.OwnerID == "" {
+			update.Unassign = true
+		} else {
+			ownerID, err := uuid.Parse(*req.OwnerID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid owner_id"})
+				return
+			}
+			update.OwnerID = &ownerID
+		}
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	updated, err := h.caseService.UpdateCase(c.Request.Context(), caseID, userID.(uuid.UUID), update)
+	if err != nil {
+		respondCaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// getTimeline returns the dated history of a case and everything in it
+func (h *CaseHandler) getTimeline(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid case ID"})
+		return
+	}
+
+	timeline, err := h.caseService.Timeline(c.Request.Context(), caseID)
+	if err != nil {
+		respondCaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
+
+// suggestForCase returns reports that may belong in a case
+func (h *CaseHandler) suggestForCase(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid case ID"})
+		return
+	}
+
+	candidates, err := h.caseService.SuggestForCase(c.Request.Context(), caseID)
+	if err != nil {
+		respondCaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, candidates)
+}
+
+// suggestForReport returns reports that may belong with a report
+func (h *CaseHandler) suggestForReport(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	candidates, err := h.caseService.SuggestForReport(c.Request.Context(), reportID)
+	if err != nil {
+		respondCaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, candidates)
+}
+
+// linkRecord adds a report, alert or sanction to a case
+func (h *CaseHandler) linkRecord(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid case ID"})
+		return
+	}
+
+	var req struct {
+		ReportID   *uuid.UUID `json:"report_id"`
+		AlertID    *uuid.UUID `json:"alert_id"`
+		SanctionID *uuid.UUID `json:"sanction_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	link, err := h.caseService.LinkRecord(c.Request.Context(), caseID, userID.(uuid.UUID), services.CaseLinkTarget{
+		ReportID:   req.ReportID,
+		AlertID:    req.AlertID,
+		SanctionID: req.SanctionID,
+	})
+	if err != nil {
+		respondCaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// unlinkRecord removes a record from a case
+func (h *CaseHandler) unlinkRecord(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid case ID"})
+		return
+	}
+	linkID, err := uuid.Parse(c.Param("linkID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid link ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.caseService.UnlinkRecord(c.Request.Context(), caseID, linkID, userID.(uuid.UUID)); err != nil {
+		respondCaseError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// listNotes returns a case's internal notes as threads
+func (h *CaseHandler) listNotes(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid case ID"})
+		return
+	}
+
+	threads, err := h.caseService.Notes(c.Request.Context(), caseID)
+	if err != nil {
+		respondCaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, threads)
+}
+
+// addNote adds an internal note to a case, or a reply to one of its notes
+func (h *CaseHandler) addNote(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid case ID"})
+		return
+	}
+
+	var req struct {
+		Body     string     `json:"body" binding:"required"`
+		ParentID *uuid.UUID `json:"parent_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	note, err := h.caseService.AddNote(c.Request.Context(), caseID, userID.(uuid.UUID), req.ParentID, req.Body)
+	if err != nil {
+		respondCaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// respondCaseError maps case errors to HTTP status codes
+func respondCaseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCaseNotFound), errors.Is(err, services.ErrCaseLinkNotFound),
+		errors.Is(err, services.ErrReportNotFound), errors.Is(err, services.ErrAlertNotFound),
+		errors.Is(err, services.ErrSanctionNotFound), errors.Is(err, services.ErrParentNoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadyInCase):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidCaseStatus), errors.Is(err, services.ErrInvalidCaseLink),
+		errors.Is(err, services.ErrInvalidCaseOwner), errors.Is(err, services.ErrTitleRequired),
+		errors.Is(err, services.ErrNoteRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
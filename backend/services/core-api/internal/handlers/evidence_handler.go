@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead allows for form boundaries and headers around the file
+const multipartOverhead = 1 << 20
+
+// EvidenceUploadRoute is the upload route; it is exempt from the global body limit
+const EvidenceUploadRoute = "/api/v1/safety/reports/:id/evidence"
+
+// EvidenceHandler handles evidence uploads and downloads
+type EvidenceHandler struct {
+	evidenceService *services.EvidenceService
+}
+
+// NewEvidenceHandler creates a new evidence handler
+func NewEvidenceHandler(evidenceService *services.EvidenceService) *EvidenceHandler {
+	return &EvidenceHandler{
+		evidenceService: evidenceService,
+	}
+}
+
+// RegisterRoutes registers the authenticated evidence routes
+func (h *EvidenceHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/safety/reports/:id/evidence", h.uploadEvidence)
+	router.GET("/safety/reports/:id/evidence", h.listEvidence)
+}
+
+// RegisterPublicRoutes registers the signed download route, which is
+// authorised by its URL signature rather than a bearer token
+func (h *EvidenceHandler) RegisterPublicRoutes(router *gin.RouterGroup) {
+	router.GET("/evidence/:id/content", h.downloadEvidence)
+}
+
+// uploadEvidence stores a multipart "file" field as evidence for a report
+func (h *EvidenceHandler) uploadEvidence(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	role, _ := c.Get("role")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.evidenceService.MaxBytes()+multipartOverhead)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrEvidenceTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "multipart field \"file\" is required"})
+		return
+	}
+	file, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	evidence, err := h.evidenceService.Upload(c.Request.Context(), reportID, userID.(uuid.UUID), role.(models.Role), services.EvidenceUpload{
+		File:     file,
+		Size:     fh.Size,
+		FileName: fh.Filename,
+		Type:     c.PostForm("type"),
+	})
+	if err != nil {
+		respondEvidenceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, evidence)
+}
+
+// listEvidence returns a report's evidence with fresh download URLs
+func (h *EvidenceHandler) listEvidence(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	role, _ := c.Get("role")
+
+	evidence, err := h.evidenceService.List(c.Request.Context(), reportID, userID.(uuid.UUID), role.(models.Role))
+	if err != nil {
+		respondEvidenceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, evidence)
+}
+
+// downloadEvidence streams an uploaded file to the holder of a valid signed URL
+func (h *EvidenceHandler) downloadEvidence(c *gin.Context) {
+	evidenceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid evidence ID"})
+		return
+	}
+	userID, err := uuid.Parse(c.Query("uid"))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": services.ErrInvalidSignature.Error()})
+		return
+	}
+	expiresUnix, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": services.ErrInvalidSignature.Error()})
+		return
+	}
+
+	evidence, rc, err := h.evidenceService.Open(c.Request.Context(), evidenceID, userID, time.Unix(expiresUnix, 0), c.Query("sig"))
+	if err != nil {
+		respondEvidenceError(c, err)
+		return
+	}
+	defer rc.Close()
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": evidence.FileName})
+	c.DataFromReader(http.StatusOK, evidence.Size, evidence.ContentType, rc, map[string]string{
+		"Content-Disposition":    disposition,
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, no-store",
+	})
+}
+
+// respondEvidenceError maps evidence errors to HTTP status codes
+func respondEvidenceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrReportNotFound), errors.Is(err, services.ErrEvidenceNotFound),
+		errors.Is(err, services.ErrUploadNotFound), errors.Is(err, services.ErrAlertNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden), errors.Is(err, services.ErrInvalidSignature):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUploadExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUploadOffsetMismatch):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEvidenceTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnsupportedType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEmptyEvidenceFile), errors.Is(err, services.ErrEvidenceNotUpload),
+		errors.Is(err, services.ErrInvalidEvidence), errors.Is(err, services.ErrInvalidUploadTarget), errors.Is(err, services.ErrNoActiveAlert):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"time"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/export"
+	"disco/core-api/internal/middleware"
+	"disco/core-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// exportWriteTimeout replaces the server write timeout while a bundle with
+// large evidence files is built and sent
+const exportWriteTimeout = 10 * time.Minute
+
+// ExportHandler handles law-enforcement export HTTP requests
+type ExportHandler struct {
+	exportService *services.ExportService
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// RegisterRoutes registers the export routes
+func (h *ExportHandler) RegisterRoutes(router *gin.RouterGroup) {
+	exports := router.Group("/admin/exports")
+	exports.Use(middleware.Require(auth.PermRecordsExport))
+	{
+		exports.POST("", h.createExport)
+		exports.GET("/public-key", h.getPublicKey)
+	}
+}
+
+// createExport builds a signed bundle for a report or alert. The archive is
+// the response body and its detached signature is in X-Bundle-Signature.
+func (h *ExportHandler) createExport(c *gin.Context) {
+	var req struct {
+		Kind      string    `json:"kind" binding:"required,oneof=report alert"`
+		ID        uuid.UUID `json:"id" binding:"required"`
+		Reference string    `json:"reference" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil {
+		slog.DebugContext(c.Request.Context(), "cannot extend write deadline", slog.Any("error", err))
+	}
+
+	// The bundle is built in full before responding so a failure part way
+	// through is reported as an error rather than a truncated archive
+	tmp, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer func() {
+		tmp.Close()
+		if err := os.Remove(tmp.Name()); err != nil {
+			slog.WarnContext(c.Request.Context(), "failed to remove export file", slog.Any("error", err))
+		}
+	}()
+
+	result, err := h.exportService.Export(c.Request.Context(), tmp, services.ExportRequest{
+		Kind:      req.Kind,
+		ID:        req.ID,
+		ActorID:   userID.(uuid.UUID),
+		Reference: req.Reference,
+	})
+	if err != nil {
+		respondExportError(c, err)
+		return
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := req.Kind + "-" + req.ID.String() + ".zip"
+	c.DataFromReader(http.StatusOK, size, "application/zip", tmp, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": name}),
+		"X-Bundle-Signature":  result.Signature,
+		"X-Bundle-Key-ID":     result.Manifest.KeyID,
+		"Cache-Control":       "private, no-store",
+	})
+}
+
+// getPublicKey returns the PEM public key recipients verify bundles with
+func (h *ExportHandler) getPublicKey(c *gin.Context) {
+	pub := h.exportService.PublicKey()
+	if pub == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": services.ErrExportDisabled.Error()})
+		return
+	}
+	pemKey, err := export.EncodePublicKey(pub)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("X-Bundle-Key-ID", export.KeyID(pub))
+	c.Data(http.StatusOK, "application/x-pem-file", pemKey)
+}
+
+// respondExportError maps export errors to HTTP status codes
+func respondExportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrReportNotFound), errors.Is(err, services.ErrAlertNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReferenceRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrExportDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const readinessCheckTimeout = 2 * time.Second
+
+// HealthHandler serves the liveness and readiness probes
+type HealthHandler struct {
+	db       *gorm.DB
+	redis    *redis.Client
+	draining atomic.Bool
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(db *gorm.DB, rdb *redis.Client) *HealthHandler {
+	return &HealthHandler{
+		db:    db,
+		redis: rdb,
+	}
+}
+
+// RegisterRoutes registers the probe routes
+func (h *HealthHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/health", h.livez)
+	router.GET("/livez", h.livez)
+	router.GET("/readyz", h.readyz)
+}
+
+// SetDraining makes the readiness probe fail so the instance is taken out of rotation
+func (h *HealthHandler) SetDraining() {
+	h.draining.Store(true)
+}
+
+// livez reports that the process is up; it deliberately ignores dependencies
+func (h *HealthHandler) livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// readyz reports whether Postgres and Redis are reachable
+func (h *HealthHandler) readyz(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	checks := gin.H{}
+	ready := true
+
+	if err := h.pingPostgres(ctx); err != nil {
+		checks["postgres"] = err.Error()
+		ready = false
+	} else {
+		checks["postgres"] = "ok"
+	}
+
+	if err := h.redis.Ping(ctx).Err(); err != nil {
+		checks["redis"] = err.Error()
+		ready = false
+	} else {
+		checks["redis"] = "ok"
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": checks})
+}
+
+func (h *HealthHandler) pingPostgres(ctx context.Context) error {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
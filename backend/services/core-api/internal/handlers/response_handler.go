@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/middleware"
+	"disco/core-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ResponseHandler handles canned response HTTP requests
+type ResponseHandler struct {
+	responseService *services.ResponseService
+}
+
+// NewResponseHandler creates a new response handler
+func NewResponseHandler(responseService *services.ResponseService) *ResponseHandler {
+	return &ResponseHandler{
+		responseService: responseService,
+	}
+}
+
+// responseRequest is the body for previewing or sending a response
+type responseRequest struct {
+	Key       string            `json:"key" binding:"required"`
+	Locale    string            `json:"locale"`
+	Variables map[string]string `json:"variables"`
+}
+
+// RegisterRoutes registers the response routes
+func (h *ResponseHandler) RegisterRoutes(router *gin.RouterGroup) {
+	safety := router.Group("/safety")
+	{
+		safety.GET("/responses", middleware.Require(auth.PermReportsModerate), h.listTemplates)
+		safety.POST("/responses", middleware.Require(auth.PermResponsesManage), h.createTemplate)
+		safety.PUT("/responses/:id", middleware.Require(auth.PermResponsesManage), h.updateTemplate)
+		safety.DELETE("/responses/:id", middleware.Require(auth.PermResponsesManage), h.archiveTemplate)
+		safety.POST("/report/:id/responses/preview", middleware.Require(auth.PermReportsModerate), h.previewResponse)
+		safety.POST("/report/:id/responses", middleware.Require(auth.PermReportsModerate), h.sendResponse)
+	}
+}
+
+// listTemplates returns the response library, optionally filtered by key or locale
+func (h *ResponseHandler) listTemplates(c *gin.Context) {
+	var archived bool
+	if v := c.Query("archived"); v != "" {
+		var err error
+		if archived, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid archived"})
+			return
+		}
+	}
+
+	templates, err := h.responseService.ListTemplates(c.Request.Context(), c.Query("key"), c.Query("locale"), archived)
+	if err != nil {
+		respondResponseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// createTemplate adds a template for a key and locale
+func (h *ResponseHandler) createTemplate(c *gin.Context) {
+	var req struct {
+		Key    string `json:"key" binding:"required"`
+		Locale string `json:"locale" binding:"required"`
+		Title  string `json:"title" binding:"required"`
+		Body   string `json:"body" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	template, err := h.responseService.CreateTemplate(c.Request.Context(), userID.(uuid.UUID), services.ResponseTemplateInput{
+		Key:    req.Key,
+		Locale: req.Locale,
+		Title:  req.Title,
+		Body:   req.Body,
+	})
+	if err != nil {
+		respondResponseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// updateTemplate replaces a template's title and body
+func (h *ResponseHandler) updateTemplate(c *gin.Context) {
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	var req struct {
+		Title string `json:"title" binding:"required"`
+		Body  string `json:"body" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	template, err := h.responseService.UpdateTemplate(c.Request.Context(), templateID, userID.(uuid.UUID), req.Title, req.Body)
+	if err != nil {
+		respondResponseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// archiveTemplate withdraws a template from the library
+func (h *ResponseHandler) archiveTemplate(c *gin.Context) {
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.responseService.ArchiveTemplate(c.Request.Context(), templateID, userID.(uuid.UUID)); err != nil {
+		respondResponseError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// previewResponse renders a response for a report without sending it
+func (h *ResponseHandler) previewResponse(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	var req responseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rendered, err := h.responseService.Preview(c.Request.Context(), reportID, services.ResponseRequest{
+		Key:       req.Key,
+		Locale:    req.Locale,
+		Variables: req.Variables,
+	})
+	if err != nil {
+		respondResponseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rendered)
+}
+
+// sendResponse sends a canned response to a report's reporter
+func (h *ResponseHandler) sendResponse(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	var req responseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	notification, err := h.responseService.Send(c.Request.Context(), reportID, userID.(uuid.UUID), services.ResponseRequest{
+		Key:       req.Key,
+		Locale:    req.Locale,
+		Variables: req.Variables,
+	})
+	if err != nil {
+		respondResponseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, notification)
+}
+
+// respondResponseError maps response errors to HTTP status codes
+func respondResponseError(c *gin.Context, err error) {
+	var missing *services.MissingVariablesError
+	var claimed *services.ReportClaimedError
+	switch {
+	case errors.Is(err, services.ErrTemplateNotFound), errors.Is(err, services.ErrReportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTemplateExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &claimed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "claimed_by": claimed.ClaimedBy, "claim_expires_at": claimed.ExpiresAt})
+	case errors.Is(err, services.ErrConflictOfInterest):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.As(err, &missing):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "missing": missing.Names})
+	case errors.Is(err, services.ErrInvalidTemplate), errors.Is(err, services.ErrInvalidLocale),
+		errors.Is(err, services.ErrInvalidVariable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,15 +1,29 @@
 package handlers
 
 import (
+	"errors"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
-	"disco/internal/models"
-	"disco/internal/services"
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/middleware"
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// Emergency routes stay open to locked-out users; the sanctions middleware exempts them
+const (
+	EmergencyAlertRoute    = "/api/v1/safety/emergency"
+	EmergencyContactsRoute = "/api/v1/safety/contacts"
+)
+
 // SafetyHandler handles safety-related HTTP requests
 type SafetyHandler struct {
 	safetyService *services.SafetyService
@@ -32,17 +46,53 @@ func (h *SafetyHandler) RegisterRoutes(router *gin.RouterGroup) {
 		safety.GET("/blocks", h.getUserBlocks)
 		safety.POST("/contacts", h.addEmergencyContact)
 		safety.GET("/contacts", h.getEmergencyContacts)
-		safety.PUT("/report/:id/status", h.updateReportStatus)
+		safety.GET("/reports", middleware.Require(auth.PermReportsRead), h.listReports)
+		safety.GET("/reports/mine", h.listMyReports)
+		safety.POST("/reports/mine/read", h.markOutcomesRead)
+		safety.GET("/reports/:id/history", middleware.Require(auth.PermReportsRead), h.getReportHistory)
+		safety.GET("/reports/:id/verify", middleware.Require(auth.PermReportsRead), h.verifyReport)
+		safety.GET("/reporters/:id/credibility", middleware.Require(auth.PermReportsRead), h.getReporterCredibility)
+		safety.PUT("/report/:id/status", middleware.Require(auth.PermReportsModerate), h.updateReportStatus)
+		safety.POST("/report/:id/reopen", middleware.Require(auth.PermReportsModerate), h.reopenReport)
+		safety.POST("/report/:id/claim", middleware.Require(auth.PermReportsModerate), h.claimReport)
+		safety.POST("/report/:id/release", middleware.Require(auth.PermReportsModerate), h.releaseReport)
+		safety.POST("/report/:id/assign", middleware.Require(auth.PermReportsModerate), h.assignReport)
+		safety.GET("/moderators/stats", middleware.Require(auth.PermReportsRead), h.getModeratorStats)
+		safety.GET("/reports/:id/notes", middleware.Require(auth.PermReportsRead), h.listReportNotes)
+		safety.POST("/report/:id/notes", middleware.Require(auth.PermReportsModerate), h.addReportNote)
 	}
 }
 
+// reportRequest is the body for filing a report. Everything else on the
+// report is set by the server.
+type reportRequest struct {
+	ReportedID  uuid.UUID           `json:"reported_id" binding:"required"`
+	Type        models.IncidentType `json:"type" binding:"required"`
+	Description string              `json:"description"`
+	Evidence    []evidenceLink      `json:"evidence"`
+}
+
+// evidenceLink is linked evidence filed with a report
+type evidenceLink struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
 // createSafetyReport handles the creation of a new safety report
 func (h *SafetyHandler) createSafetyReport(c *gin.Context) {
-	var report models.SafetyReport
-	if err := c.ShouldBindJSON(&report); err != nil {
+	var req reportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	report := models.SafetyReport{
+		ReportedID:  req.ReportedID,
+		Type:        req.Type,
+		Description: req.Description,
+	}
+	for _, link := range req.Evidence {
+		report.Evidence = append(report.Evidence, models.Evidence{Type: link.Type, URL: link.URL})
+	}
 
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("userID")
@@ -51,9 +101,21 @@ func (h *SafetyHandler) createSafetyReport(c *gin.Context) {
 		return
 	}
 	report.ReporterID = userID.(uuid.UUID)
+	// Set by the sanctions middleware for shadow-restricted users
+	report.Shadowed = c.GetBool("restricted")
 
 	if err := h.safetyService.CreateSafetyReport(c.Request.Context(), &report); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		var duplicate *services.DuplicateReportError
+		var limited *services.ReportLimitError
+		switch {
+		case errors.As(err, &duplicate):
+			c.JSON(http.StatusConflict, gin.H{"error": services.ErrDuplicateReport.Error(), "report_id": duplicate.ExistingID})
+		case errors.As(err, &limited):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -162,7 +224,7 @@ func (h *SafetyHandler) getEmergencyContacts(c *gin.Context) {
 	c.JSON(http.StatusOK, contacts)
 }
 
-// updateReportStatus updates the status of a safety report
+// updateReportStatus moves a safety report along the review flow
 func (h *SafetyHandler) updateReportStatus(c *gin.Context) {
 	reportID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -170,18 +232,458 @@ func (h *SafetyHandler) updateReportStatus(c *gin.Context) {
 		return
 	}
 
-	var status struct {
+	var req struct {
 		Status models.IncidentStatus `json:"status" binding:"required"`
+		Note   string                `json:"note"`
+		// Action sanctions the reported user; only allowed when resolving
+		Action *struct {
+			Type     models.SanctionType `json:"type" binding:"required"`
+			Duration string              `json:"duration"`
+			Reason   string              `json:"reason"`
+		} `json:"action"`
 	}
-	if err := c.ShouldBindJSON(&status); err != nil {
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := h.safetyService.UpdateSafetyReportStatus(c.Request.Context(), reportID, status.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var action *services.SanctionAction
+	if req.Action != nil {
+		duration, err := parseOptionalDuration(req.Action.Duration)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		action = &services.SanctionAction{Type: req.Action.Type, Duration: duration, Reason: req.Action.Reason}
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.safetyService.UpdateSafetyReportStatus(c.Request.Context(), reportID, userID.(uuid.UUID), req.Status, req.Note, action); err != nil {
+		respondReportError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Report status updated"})
 }
+
+// reopenReport returns a resolved or dismissed report to review
+func (h *SafetyHandler) reopenReport(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	var req struct {
+		Note string `json:"note" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.safetyService.ReopenReport(c.Request.Context(), reportID, userID.(uuid.UUID), req.Note); err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Report reopened"})
+}
+
+// claimReport takes or renews the caller's review claim on a report
+func (h *SafetyHandler) claimReport(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	report, err := h.safetyService.ClaimReport(c.Request.Context(), reportID, userID.(uuid.UUID))
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// releaseReport gives up the caller's claim on a report
+func (h *SafetyHandler) releaseReport(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.safetyService.ReleaseReport(c.Request.Context(), reportID, userID.(uuid.UUID)); err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Report released"})
+}
+
+// assignReport assigns a report to a moderator, or by the configured
+// strategy when no assignee is given
+func (h *SafetyHandler) assignReport(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	var req struct {
+		AssigneeID *uuid.UUID `json:"assignee_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	report, err := h.safetyService.AssignReport(c.Request.Context(), reportID, userID.(uuid.UUID), req.AssigneeID)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// getModeratorStats returns each moderator's workload and throughput.
+// The period defaults to the last seven days.
+func (h *SafetyHandler) getModeratorStats(c *gin.Context) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -7)
+	for param, dst := range map[string]*time.Time{
+		"from": &from,
+		"to":   &to,
+	} {
+		if v := c.Query(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + ": want an RFC 3339 timestamp"})
+				return
+			}
+			*dst = t
+		}
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	stats, err := h.safetyService.ModeratorStats(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"from": from, "to": to, "moderators": stats})
+}
+
+// listReportNotes returns a report's internal notes as threads
+func (h *SafetyHandler) listReportNotes(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	threads, err := h.safetyService.ReportNotes(c.Request.Context(), reportID)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, threads)
+}
+
+// addReportNote adds an internal note to a report, or a reply to one of its notes
+func (h *SafetyHandler) addReportNote(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	var req struct {
+		Body     string     `json:"body" binding:"required"`
+		ParentID *uuid.UUID `json:"parent_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	note, err := h.safetyService.AddReportNote(c.Request.Context(), reportID, userID.(uuid.UUID), req.ParentID, req.Body)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// getReportHistory returns the status changes of a safety report
+func (h *SafetyHandler) getReportHistory(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	events, err := h.safetyService.GetReportHistory(c.Request.Context(), reportID)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// verifyReport re-walks a report's hash chain and lists any breaks
+func (h *SafetyHandler) verifyReport(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	result, err := h.safetyService.VerifyReportChain(c.Request.Context(), reportID)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// getReporterCredibility returns how a reporter's past reports were decided
+func (h *SafetyHandler) getReporterCredibility(c *gin.Context) {
+	reporterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reporter ID"})
+		return
+	}
+
+	credibility, err := h.safetyService.ReporterCredibility(c.Request.Context(), reporterID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, credibility)
+}
+
+// respondReportError maps report workflow errors to HTTP status codes
+func respondReportError(c *gin.Context, err error) {
+	var claimed *services.ReportClaimedError
+	switch {
+	case errors.Is(err, services.ErrReportNotFound), errors.Is(err, services.ErrParentNoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.As(err, &claimed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "claimed_by": claimed.ClaimedBy, "claim_expires_at": claimed.ExpiresAt})
+	case errors.Is(err, services.ErrConflictOfInterest):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTransition), errors.Is(err, services.ErrNotClaimHolder),
+		errors.Is(err, services.ErrNoModerators):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidStatus), errors.Is(err, services.ErrNoteRequired),
+		errors.Is(err, services.ErrInvalidSanction), errors.Is(err, services.ErrSanctionNotResolved),
+		errors.Is(err, services.ErrInvalidAssignee), errors.Is(err, services.ErrAssignmentDisabled):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// listMyReports returns the caller's own reports with their outcomes
+func (h *SafetyHandler) listMyReports(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	page, err := h.safetyService.ListMyReports(c.Request.Context(), userID.(uuid.UUID), c.Query("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// markOutcomesRead clears the caller's unread report notifications
+func (h *SafetyHandler) markOutcomesRead(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.safetyService.MarkOutcomesRead(c.Request.Context(), userID.(uuid.UUID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// listReports returns a page of the moderator report queue
+func (h *SafetyHandler) listReports(c *gin.Context) {
+	filter, err := parseReportFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.safetyService.ListReports(c.Request.Context(), filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCursor), errors.Is(err, services.ErrInvalidSort):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parseReportFilter reads the queue filters from the query string. Status and
+// type accept comma-separated lists; from and to are RFC 3339 timestamps.
+func parseReportFilter(c *gin.Context) (services.ReportFilter, error) {
+	filter := services.ReportFilter{
+		Sort:   services.ReportSort(c.Query("sort")),
+		Cursor: c.Query("cursor"),
+	}
+
+	for _, v := range splitList(c.Query("status")) {
+		status := models.IncidentStatus(v)
+		if !status.Valid() {
+			return filter, errors.New("invalid status: " + v)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	for _, v := range splitList(c.Query("type")) {
+		t := models.IncidentType(v)
+		if !t.Valid() {
+			return filter, errors.New("invalid type: " + v)
+		}
+		filter.Types = append(filter.Types, t)
+	}
+
+	for param, dst := range map[string]**uuid.UUID{
+		"reporter_id": &filter.ReporterID,
+		"reported_id": &filter.ReportedID,
+		"assignee_id": &filter.AssigneeID,
+	} {
+		if v := c.Query(param); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return filter, errors.New("invalid " + param)
+			}
+			*dst = &id
+		}
+	}
+
+	for param, dst := range map[string]**time.Time{
+		"from": &filter.From,
+		"to":   &filter.To,
+	} {
+		if v := c.Query(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, errors.New("invalid " + param + ": want an RFC 3339 timestamp")
+			}
+			*dst = &t
+		}
+	}
+
+	if v := c.Query("overdue"); v != "" {
+		overdue, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, errors.New("invalid overdue")
+		}
+		filter.Overdue = &overdue
+	}
+
+	if v := c.Query("unassigned"); v != "" {
+		unassigned, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, errors.New("invalid unassigned")
+		}
+		filter.Unassigned = unassigned
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return filter, errors.New("invalid limit")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+// splitList splits a comma-separated query value, dropping empty items
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
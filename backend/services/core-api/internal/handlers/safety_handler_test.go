@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"disco/core-api/internal/middleware"
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/services"
+	"disco/core-api/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// lockedOut restricts every user with its sanction
+type lockedOut struct {
+	sanction *models.UserSanction
+}
+
+func (l lockedOut) Restriction(ctx context.Context, userID uuid.UUID) (*models.UserSanction, error) {
+	return l.sanction, nil
+}
+
+func TestLockedOutUserCanTriggerEmergencyAlert(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// The alert is only written, so statements are built without a database
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	safety := services.NewSafetyService(db, websocket.NewHub(nil), services.ReportLimits{}, nil, 0, nil)
+
+	for _, sanctionType := range []models.SanctionType{models.SanctionSuspension, models.SanctionBan} {
+		t.Run(string(sanctionType), func(t *testing.T) {
+			expires := time.Now().Add(time.Hour)
+			router := gin.New()
+			api := router.Group("/api/v1")
+			api.Use(func(c *gin.Context) { c.Set("userID", uuid.New()) })
+			api.Use(middleware.Sanctions(lockedOut{&models.UserSanction{ID: uuid.New(), Type: sanctionType, ExpiresAt: &expires}},
+				LockoutExemptRoutes...))
+			NewSafetyHandler(safety).RegisterRoutes(api)
+
+			tests := []struct {
+				path string
+				body string
+				want int
+			}{
+				{EmergencyAlertRoute, `{"latitude": 51.5, "longitude": -0.12}`, http.StatusCreated},
+				{"/api/v1/safety/report", `{"reported_id": "` + uuid.NewString() + `", "type": "harassment"}`, http.StatusForbidden},
+				{"/api/v1/safety/block", `{"blocked_id": "` + uuid.NewString() + `"}`, http.StatusForbidden},
+			}
+			for _, tt := range tests {
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+				req.Header.Set("Content-Type", "application/json")
+				router.ServeHTTP(w, req)
+				if w.Code != tt.want {
+					t.Errorf("POST %s = %d %s, want %d", tt.path, w.Code, w.Body.String(), tt.want)
+				}
+			}
+		})
+	}
+}
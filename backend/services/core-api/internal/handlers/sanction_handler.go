@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/middleware"
+	"disco/core-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MySanctionsRoute lets locked-out users see why; the sanctions middleware exempts it
+const MySanctionsRoute = "/api/v1/safety/sanctions/mine"
+
+// LockoutExemptRoutes stay open to suspended and banned users and their
+// appeal-scoped tokens: signing out, seeing and appealing their sanctions,
+// and everything needed to raise an emergency alert
+var LockoutExemptRoutes = []string{
+	LogoutRoute,
+	MySanctionsRoute,
+	FileAppealRoute,
+	MyAppealsRoute,
+	EmergencyAlertRoute,
+	EmergencyContactsRoute,
+	TusCreateRoute,
+	TusChunkRoute,
+}
+
+// SanctionHandler handles account sanction HTTP requests
+type SanctionHandler struct {
+	sanctionService *services.SanctionService
+}
+
+// NewSanctionHandler creates a new sanction handler
+func NewSanctionHandler(sanctionService *services.SanctionService) *SanctionHandler {
+	return &SanctionHandler{
+		sanctionService: sanctionService,
+	}
+}
+
+// RegisterRoutes registers the sanction routes
+func (h *SanctionHandler) RegisterRoutes(router *gin.RouterGroup) {
+	safety := router.Group("/safety")
+	{
+		safety.GET("/sanctions/mine", h.listMySanctions)
+		safety.GET("/users/:id/sanctions", middleware.Require(auth.PermReportsRead), h.listUserSanctions)
+		safety.GET("/sanctions/:id/history", middleware.Require(auth.PermReportsRead), h.sanctionHistory)
+		safety.POST("/sanctions/:id/lift", middleware.Require(auth.PermReportsModerate), h.liftSanction)
+		safety.POST("/sanctions/:id/confirm", middleware.Require(auth.PermReportsModerate), h.confirmSanction)
+	}
+}
+
+// listMySanctions returns the sanctions placed on the caller
+func (h *SanctionHandler) listMySanctions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sanctions, err := h.sanctionService.ListOwnSanctions(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sanctions)
+}
+
+// listUserSanctions returns a user's sanction history for moderators
+func (h *SanctionHandler) listUserSanctions(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	sanctions, err := h.sanctionService.ListUserSanctions(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sanctions)
+}
+
+// sanctionHistory returns the appeal steps taken on a sanction
+func (h *SanctionHandler) sanctionHistory(c *gin.Context) {
+	sanctionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sanction ID"})
+		return
+	}
+
+	events, err := h.sanctionService.History(c.Request.Context(), sanctionID)
+	if err != nil {
+		respondSanctionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// liftSanction ends a sanction early
+func (h *SanctionHandler) liftSanction(c *gin.Context) {
+	sanctionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sanction ID"})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sanction, err := h.sanctionService.LiftSanction(c.Request.Context(), sanctionID, userID.(uuid.UUID), req.Reason)
+	if err != nil {
+		respondSanctionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sanction)
+}
+
+// confirmSanction upholds an automatic sanction pending review
+func (h *SanctionHandler) confirmSanction(c *gin.Context) {
+	sanctionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sanction ID"})
+		return
+	}
+
+	var req struct {
+		Duration string `json:"duration"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	duration, err := parseOptionalDuration(req.Duration)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sanction, err := h.sanctionService.ConfirmSanction(c.Request.Context(), sanctionID, userID.(uuid.UUID), duration)
+	if err != nil {
+		respondSanctionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sanction)
+}
+
+// respondSanctionError maps sanction errors to HTTP status codes
+func respondSanctionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSanctionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSanctionLifted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidSanction), errors.Is(err, services.ErrNoteRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// parseOptionalDuration parses a Go duration such as "72h"; empty means zero
+func parseOptionalDuration(v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, errors.New("invalid duration: want a positive Go duration such as 72h")
+	}
+	return d, nil
+}
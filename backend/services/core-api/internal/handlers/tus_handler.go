@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// tus protocol constants; see https://tus.io/protocols/resumable-upload
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,termination,expiration"
+	tusChunkType  = "application/offset+octet-stream"
+)
+
+// TusCreateRoute starts an upload. Evidence for an emergency alert must
+// reach it even from locked-out users, so the sanctions middleware exempts
+// it and TusChunkRoute.
+const TusCreateRoute = "/api/v1/uploads"
+
+// TusChunkRoute receives upload chunks; it is exempt from the global body limit
+const TusChunkRoute = "/api/v1/uploads/:id"
+
+// TusHandler implements the tus resumable upload protocol for evidence
+type TusHandler struct {
+	evidenceService *services.EvidenceService
+	chunkTimeout    time.Duration
+}
+
+// NewTusHandler creates a new tus handler. chunkTimeout replaces the server's
+// read and write timeouts for a single PATCH, which may be slow on mobile links.
+func NewTusHandler(evidenceService *services.EvidenceService, chunkTimeout time.Duration) *TusHandler {
+	return &TusHandler{
+		evidenceService: evidenceService,
+		chunkTimeout:    chunkTimeout,
+	}
+}
+
+// RegisterPublicRoutes registers protocol discovery, which needs no token
+func (h *TusHandler) RegisterPublicRoutes(router *gin.RouterGroup) {
+	router.OPTIONS("/uploads", h.options)
+	router.OPTIONS("/uploads/:id", h.options)
+}
+
+// RegisterRoutes registers the authenticated upload routes
+func (h *TusHandler) RegisterRoutes(router *gin.RouterGroup) {
+	uploads := router.Group("/uploads")
+	uploads.Use(requireTusResumable)
+	{
+		uploads.POST("", h.createUpload)
+		uploads.HEAD("/:id", h.getOffset)
+		uploads.PATCH("/:id", h.writeChunk)
+		uploads.DELETE("/:id", h.terminateUpload)
+	}
+}
+
+// requireTusResumable rejects clients speaking another protocol version
+func requireTusResumable(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	if c.GetHeader("Tus-Resumable") != tusVersion {
+		c.Header("Tus-Version", tusVersion)
+		c.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{"error": "unsupported tus version"})
+		return
+	}
+	c.Next()
+}
+
+// options advertises the supported protocol version and extensions
+func (h *TusHandler) options(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", tusExtensions)
+	c.Header("Tus-Max-Size", strconv.FormatInt(h.evidenceService.ResumableMaxBytes(), 10))
+	c.Status(http.StatusNoContent)
+}
+
+// createUpload starts an upload. Upload-Metadata may carry report_id or
+// alert_id, filename and type; without a target the upload is attached to the
+// caller's active emergency alert.
+func (h *TusHandler) createUpload(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	role, _ := c.Get("role")
+
+	if c.GetHeader("Upload-Defer-Length") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Defer-Length is not supported"})
+		return
+	}
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Upload-Length"})
+		return
+	}
+
+	metadata, err := parseUploadMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	target := services.UploadTarget{
+		FileName: metadata["filename"],
+		Type:     metadata["type"],
+	}
+	for key, dst := range map[string]**uuid.UUID{
+		"report_id": &target.ReportID,
+		"alert_id":  &target.AlertID,
+	} {
+		if v, ok := metadata[key]; ok {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + key})
+				return
+			}
+			*dst = &id
+		}
+	}
+
+	upload, err := h.evidenceService.CreateUpload(c.Request.Context(), userID.(uuid.UUID), role.(models.Role), length, target)
+	if err != nil {
+		respondEvidenceError(c, err)
+		return
+	}
+
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+upload.ID.String())
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusCreated)
+}
+
+// getOffset reports how many bytes the server holds so the client can resume
+func (h *TusHandler) getOffset(c *gin.Context) {
+	upload, ok := h.lookup(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	setUploadHeaders(c, upload)
+	c.Status(http.StatusOK)
+}
+
+// writeChunk appends the request body at Upload-Offset
+func (h *TusHandler) writeChunk(c *gin.Context) {
+	uploadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrUploadNotFound.Error()})
+		return
+	}
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if c.ContentType() != tusChunkType {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + tusChunkType})
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Upload-Offset"})
+		return
+	}
+
+	// Chunks from a slow connection can outlast the server-wide timeouts
+	rc := http.NewResponseController(c.Writer)
+	deadline := time.Now().Add(h.chunkTimeout)
+	if err := rc.SetReadDeadline(deadline); err != nil {
+		slog.DebugContext(c.Request.Context(), "cannot extend read deadline", slog.Any("error", err))
+	}
+	if err := rc.SetWriteDeadline(deadline); err != nil {
+		slog.DebugContext(c.Request.Context(), "cannot extend write deadline", slog.Any("error", err))
+	}
+
+	upload, err := h.evidenceService.WriteChunk(c.Request.Context(), uploadID, userID.(uuid.UUID), offset, c.Request.Body)
+	if err != nil {
+		respondEvidenceError(c, err)
+		return
+	}
+
+	setUploadHeaders(c, upload)
+	c.Status(http.StatusNoContent)
+}
+
+// terminateUpload cancels an unfinished upload
+func (h *TusHandler) terminateUpload(c *gin.Context) {
+	uploadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrUploadNotFound.Error()})
+		return
+	}
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.evidenceService.TerminateUpload(c.Request.Context(), uploadID, userID.(uuid.UUID)); err != nil {
+		respondEvidenceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// lookup loads the caller's upload named in the path, writing the error response on failure
+func (h *TusHandler) lookup(c *gin.Context) (*models.ResumableUpload, bool) {
+	uploadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return nil, false
+	}
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.Status(http.StatusUnauthorized)
+		return nil, false
+	}
+
+	upload, err := h.evidenceService.GetUpload(c.Request.Context(), uploadID, userID.(uuid.UUID))
+	if err != nil {
+		// HEAD responses carry no body, so only the status is meaningful
+		switch {
+		case errors.Is(err, services.ErrUploadNotFound):
+			c.Status(http.StatusNotFound)
+		case errors.Is(err, services.ErrUploadExpired):
+			c.Status(http.StatusGone)
+		default:
+			slog.ErrorContext(c.Request.Context(), "upload lookup failed", slog.Any("error", err))
+			c.Status(http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+	return upload, true
+}
+
+// setUploadHeaders reports the upload's progress, and the evidence it became once complete
+func setUploadHeaders(c *gin.Context, upload *models.ResumableUpload) {
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	if upload.CompletedAt == nil {
+		c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+	if upload.EvidenceID != nil {
+		c.Header("X-Evidence-ID", upload.EvidenceID.String())
+	}
+}
+
+// parseUploadMetadata decodes "key base64value,key2 base64value2"
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("invalid Upload-Metadata")
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.New("invalid Upload-Metadata value for " + key)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
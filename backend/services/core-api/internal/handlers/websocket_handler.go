@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"disco/core-api/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WebsocketHandler upgrades authenticated requests to hub connections
+type WebsocketHandler struct {
+	hub *websocket.Hub
+}
+
+// NewWebsocketHandler creates a new websocket handler
+func NewWebsocketHandler(hub *websocket.Hub) *WebsocketHandler {
+	return &WebsocketHandler{
+		hub: hub,
+	}
+}
+
+// RegisterRoutes registers the websocket routes
+func (h *WebsocketHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/ws", h.connect)
+}
+
+// connect upgrades the request and attaches the connection to the hub
+func (h *WebsocketHandler) connect(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// The upgrader writes its own error response on failure
+	_ = h.hub.ServeWS(c.Writer, c.Request, userID.(uuid.UUID))
+}
@@ -0,0 +1,90 @@
+// Package metrics defines the Prometheus collectors exported by core-api on /metrics
+package metrics
+
+import (
+	sharedmetrics "disco/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "core_api"
+
+var (
+	// HTTPRequestDuration observes request latency per matched route
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// WebsocketConnections tracks clients registered with the hub
+	WebsocketConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "active_connections",
+		Help:      "Websocket clients currently registered with the hub.",
+	})
+
+	// WebsocketMessagesDropped counts messages not delivered because a client's buffer was full
+	WebsocketMessagesDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "messages_dropped_total",
+		Help:      "Messages dropped because the receiving client was too slow; the client is disconnected.",
+	}, []string{"message_type"})
+
+	// EmergencyAlerts counts emergency alerts created, by alert type
+	EmergencyAlerts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "safety",
+		Name:      "emergency_alerts_total",
+		Help:      "Emergency alerts created, by type.",
+	}, []string{"type"})
+
+	// ReportsRejected counts safety reports refused as duplicates or over a rate limit
+	ReportsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "safety",
+		Name:      "reports_rejected_total",
+		Help:      "Safety reports rejected, by reason.",
+	}, []string{"reason"})
+
+	// ReportEscalations counts escalation rules fired, by rule name
+	ReportEscalations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "safety",
+		Name:      "report_escalations_total",
+		Help:      "Escalation rules fired against reported users, by rule.",
+	}, []string{"rule"})
+
+	// ReportSLABreaches counts reports that passed their SLA deadline while open, by type
+	ReportSLABreaches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "safety",
+		Name:      "report_sla_breaches_total",
+		Help:      "Reports still open after their SLA deadline, by incident type.",
+	}, []string{"type"})
+
+	// SanctionsIssued counts sanctions placed on users, by sanction type
+	SanctionsIssued = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "safety",
+		Name:      "sanctions_issued_total",
+		Help:      "Sanctions placed on user accounts, by sanction type.",
+	}, []string{"type"})
+
+	// ReportAssignments counts reports assigned to moderators, by method:
+	// manual, claim or the automatic strategy
+	ReportAssignments = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "safety",
+		Name:      "report_assignments_total",
+		Help:      "Reports assigned to moderators, by assignment method.",
+	}, []string{"method"})
+
+	// Redis instruments every command sent by the core-api Redis client
+	Redis = sharedmetrics.NewRedisHook(namespace, prometheus.DefaultRegisterer)
+)
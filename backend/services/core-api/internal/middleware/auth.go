@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"disco/core-api/internal/auth"
+	"disco/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Auth validates the bearer token and stores the caller's user ID in the context
+func Auth(tokens *auth.TokenService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		claims, err := tokens.Validate(c.Request.Context(), token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		userID, err := claims.UserID()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		c.Set("userID", userID)
+		c.Set("role", claims.Role)
+		c.Set("claims", claims)
+		c.Request = c.Request.WithContext(
+			logging.With(c.Request.Context(), slog.String(logging.UserIDKey, userID.String())),
+		)
+
+		c.Next()
+	}
+}
+
+// bearerToken extracts the token from the Authorization header. Browsers
+// cannot set headers on websocket upgrades, so those may pass it as a query parameter.
+func bearerToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		return c.Query("access_token")
+	}
+
+	return ""
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"disco/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the correlation ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID reuses the caller's X-Request-ID or generates one, echoes it in the
+// response and stores it in the request context for logging
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = uuid.NewString()
+		}
+
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(
+			logging.With(c.Request.Context(), slog.String(logging.RequestIDKey, requestID)),
+		)
+
+		c.Next()
+	}
+}
+
+// Logger writes one structured access log line per request
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		level := slog.LevelInfo
+		switch status := c.Writer.Status(); {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
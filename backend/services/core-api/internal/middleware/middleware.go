@@ -1,20 +1,36 @@
 package middleware
 
 import (
+	"net/http"
+
+	"disco/pkg/cors"
+
 	"github.com/gin-gonic/gin"
 )
 
-func Cors() gin.HandlerFunc {
+// Cors applies the configured origin policy and answers preflight requests
+func Cors(policy *cors.Policy) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if policy.Handle(c.Writer, c.Request) {
+			c.Abort()
 			return
 		}
-		
+
+		c.Next()
+	}
+}
+
+// BodyLimit caps the size of request bodies; reads past the limit fail.
+// Routes listed in exempt, by their registered path, apply their own limit.
+func BodyLimit(maxBytes int64, exempt ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(exempt))
+	for _, route := range exempt {
+		skip[route] = true
+	}
+	return func(c *gin.Context) {
+		if !skip[c.FullPath()] {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
 		c.Next()
 	}
 }
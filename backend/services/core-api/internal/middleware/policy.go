@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Require rejects callers whose role has not been granted every listed permission.
+// It must run after Auth.
+func Require(perms ...auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+
+		r, _ := role.(models.Role)
+		for _, perm := range perms {
+			if !auth.Allowed(r, perm) {
+				slog.WarnContext(c.Request.Context(), "authorization denied",
+					slog.String("role", string(r)),
+					slog.String("permission", string(perm)),
+					slog.String("method", c.Request.Method),
+					slog.String("route", c.FullPath()),
+				)
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
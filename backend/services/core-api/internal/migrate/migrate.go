@@ -0,0 +1,278 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// advisoryLockKey identifies the core-api migration lock. Every replica
+// takes it before touching the schema so concurrent startups run one at a time.
+const advisoryLockKey int64 = 0x646973636f6d6967 // "discomig"
+
+var (
+	ErrDirty          = errors.New("database is dirty, fix the failed migration and run force")
+	ErrUnknownVersion = errors.New("unknown migration version")
+)
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a numbered pair of up and down SQL scripts
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes whether a migration has been applied
+type Status struct {
+	Migration
+	Applied bool
+}
+
+// Migrator applies embedded migrations to a Postgres database
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New loads the migrations found in fsys
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse version of %s: %w", entry.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies every pending migration and returns how many were applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return ErrDirty
+		}
+
+		for _, mig := range m.migrations {
+			if mig.Version <= current {
+				continue
+			}
+			if err := apply(ctx, conn, mig.Version, mig.Version, mig.Up); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", mig.Version, mig.Name, err)
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts up to steps applied migrations, newest first
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return ErrDirty
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			mig := m.migrations[i]
+			if mig.Version > current {
+				continue
+			}
+
+			var previous int64
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+			if err := apply(ctx, conn, mig.Version, previous, mig.Down); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", mig.Version, mig.Name, err)
+			}
+			current = previous
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status reports every known migration along with the current version and dirty flag
+func (m *Migrator) Status(ctx context.Context) ([]Status, int64, bool, error) {
+	var (
+		statuses []Status
+		current  int64
+		dirty    bool
+	)
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		var err error
+		current, dirty, err = readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			statuses = append(statuses, Status{
+				Migration: mig,
+				Applied:   mig.Version <= current,
+			})
+		}
+		return nil
+	})
+	return statuses, current, dirty, err
+}
+
+// Force records version as the current clean version without running any SQL.
+// It is used to recover after a failed migration has been fixed by hand.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if version != 0 && !m.known(version) {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		return setVersion(ctx, conn, version, false)
+	})
+}
+
+func (m *Migrator) known(version int64) bool {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// withLock runs fn on a single connection holding the migration advisory lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockKey)
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT NOT NULL PRIMARY KEY,
+    dirty BOOLEAN NOT NULL
+)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// apply marks the target version dirty, runs the script in a transaction and
+// marks the resulting version clean. A failure leaves the dirty flag set.
+func apply(ctx context.Context, conn *sql.Conn, dirtyVersion, cleanVersion int64, script string) error {
+	if err := setVersion(ctx, conn, dirtyVersion, true); err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return setVersion(ctx, conn, cleanVersion, false)
+}
+
+func readVersion(ctx context.Context, conn *sql.Conn) (int64, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+func setVersion(ctx context.Context, conn *sql.Conn, version int64, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if version > 0 || dirty {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)", version, dirty); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AppealStatus string
+
+const (
+	AppealPending AppealStatus = "pending"
+	// AppealUpheld leaves the sanction as it was
+	AppealUpheld AppealStatus = "upheld"
+	// AppealReduced shortens the sanction; a ban becomes a suspension
+	AppealReduced AppealStatus = "reduced"
+	// AppealOverturned lifts the sanction
+	AppealOverturned AppealStatus = "overturned"
+)
+
+// Decision reports whether s is an outcome a reviewer can decide
+func (s AppealStatus) Decision() bool {
+	switch s {
+	case AppealUpheld, AppealReduced, AppealOverturned:
+		return true
+	}
+	return false
+}
+
+// SanctionAppeal is a user's request to reconsider a sanction. It must be
+// decided by a moderator other than the one who issued the sanction.
+type SanctionAppeal struct {
+	ID           uuid.UUID     `json:"id" gorm:"primaryKey;type:uuid"`
+	SanctionID   uuid.UUID     `json:"sanction_id" gorm:"type:uuid;not null"`
+	Sanction     *UserSanction `json:"sanction,omitempty" gorm:"foreignKey:SanctionID"`
+	UserID       uuid.UUID     `json:"user_id" gorm:"type:uuid;not null"`
+	Statement    string        `json:"statement" gorm:"not null"`
+	Status       AppealStatus  `json:"status" gorm:"not null;default:'pending'"`
+	ReviewerID   *uuid.UUID    `json:"reviewer_id,omitempty" gorm:"type:uuid"`
+	DecisionNote string        `json:"decision_note,omitempty" gorm:"default:null"`
+	CreatedAt    time.Time     `json:"created_at"`
+	DecidedAt    *time.Time    `json:"decided_at"`
+}
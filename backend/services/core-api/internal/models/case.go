@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CaseStatus string
+
+const (
+	CaseOpen          CaseStatus = "open"
+	CaseInvestigating CaseStatus = "investigating"
+	CaseClosed        CaseStatus = "closed"
+)
+
+// Valid reports whether s is a known case status
+func (s CaseStatus) Valid() bool {
+	switch s {
+	case CaseOpen, CaseInvestigating, CaseClosed:
+		return true
+	}
+	return false
+}
+
+// Case groups the reports, alerts and sanctions of one investigation,
+// usually about one user: the Subject
+type Case struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	Title     string     `json:"title" gorm:"not null"`
+	Status    CaseStatus `json:"status" gorm:"not null;default:'open'"`
+	SubjectID *uuid.UUID `json:"subject_id,omitempty" gorm:"type:uuid"`
+	OwnerID   *uuid.UUID `json:"owner_id,omitempty" gorm:"type:uuid"`
+	CreatedBy uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+}
+
+// CaseLink attaches exactly one report, alert or sanction to a case. A
+// record can be in one case at a time.
+type CaseLink struct {
+	ID         uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	CaseID     uuid.UUID  `json:"case_id" gorm:"type:uuid;not null"`
+	ReportID   *uuid.UUID `json:"report_id,omitempty" gorm:"type:uuid"`
+	AlertID    *uuid.UUID `json:"alert_id,omitempty" gorm:"type:uuid"`
+	SanctionID *uuid.UUID `json:"sanction_id,omitempty" gorm:"type:uuid"`
+	AddedBy    uuid.UUID  `json:"added_by" gorm:"type:uuid;not null"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CaseEvent records a change made to a case itself
+type CaseEvent struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid"`
+	CaseID    uuid.UUID `json:"case_id" gorm:"type:uuid;not null"`
+	ActorID   uuid.UUID `json:"actor_id" gorm:"type:uuid;not null"`
+	Action    string    `json:"action" gorm:"not null"`
+	Detail    string    `json:"detail,omitempty" gorm:"default:null"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportEscalation records an escalation rule firing for a reported user
+type ReportEscalation struct {
+	ID                uuid.UUID `json:"id" gorm:"primaryKey;type:uuid"`
+	Rule              string    `json:"rule" gorm:"not null"`
+	ReportedID        uuid.UUID `json:"reported_id" gorm:"type:uuid;not null"`
+	ReportID          uuid.UUID `json:"report_id" gorm:"type:uuid;not null"`
+	Reports           int       `json:"reports" gorm:"not null"`
+	DistinctReporters int       `json:"distinct_reporters" gorm:"not null"`
+	Actions           []string  `json:"actions" gorm:"type:text[]"`
+	CreatedAt         time.Time `json:"created_at"`
+}
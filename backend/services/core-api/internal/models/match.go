@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Match represents a pairing between two users
+type Match struct {
+	ID        uuid.UUID   `json:"id" gorm:"primaryKey;type:uuid"`
+	Users     []uuid.UUID `json:"users" gorm:"type:uuid[];not null"`
+	Score     float64     `json:"score"`
+	Status    string      `json:"status" gorm:"not null;default:'pending'"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InternalNote is a staff-only note on a report or a case. Reporters and
+// reported users never see notes. A note with a ParentID replies to
+// another note on the same report or case.
+type InternalNote struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	ReportID  *uuid.UUID `json:"report_id,omitempty" gorm:"type:uuid"`
+	CaseID    *uuid.UUID `json:"case_id,omitempty" gorm:"type:uuid"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid"`
+	AuthorID  uuid.UUID  `json:"author_id" gorm:"type:uuid;not null"`
+	Body      string     `json:"body" gorm:"not null"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NoteThread is a note with its replies, oldest first
+type NoteThread struct {
+	InternalNote
+	Replies []NoteThread `json:"replies"`
+}
+
+// BuildNoteThreads arranges notes, oldest first, into threads. A reply
+// whose parent is missing is treated as the start of a thread.
+func BuildNoteThreads(notes []InternalNote) []NoteThread {
+	children := make(map[uuid.UUID][]InternalNote)
+	present := make(map[uuid.UUID]bool, len(notes))
+	for _, n := range notes {
+		present[n.ID] = true
+	}
+	var roots []InternalNote
+	for _, n := range notes {
+		if n.ParentID != nil && present[*n.ParentID] {
+			children[*n.ParentID] = append(children[*n.ParentID], n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+
+	var build func(n InternalNote) NoteThread
+	build = func(n InternalNote) NoteThread {
+		thread := NoteThread{InternalNote: n, Replies: []NoteThread{}}
+		for _, child := range children[n.ID] {
+			thread.Replies = append(thread.Replies, build(child))
+		}
+		return thread
+	}
+	threads := make([]NoteThread, 0, len(roots))
+	for _, n := range roots {
+		threads = append(threads, build(n))
+	}
+	return threads
+}
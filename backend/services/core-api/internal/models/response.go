@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResponseTemplate is a canned response moderators send to reporters. Key
+// names the response; each locale has its own template under the key.
+// Body may use {{variable}} placeholders, filled in when it is sent.
+type ResponseTemplate struct {
+	ID         uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	Key        string     `json:"key" gorm:"not null"`
+	Locale     string     `json:"locale" gorm:"not null"`
+	Title      string     `json:"title" gorm:"not null"`
+	Body       string     `json:"body" gorm:"not null"`
+	CreatedBy  uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+	UpdatedBy  uuid.UUID  `json:"updated_by" gorm:"type:uuid;not null"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+
+	// Variables lists the placeholders Body uses
+	Variables []string `json:"variables" gorm:"-"`
+}
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,23 +13,182 @@ import (
 type IncidentType string
 
 const (
-	IncidentTypeHarassment     IncidentType = "harassment"
-	IncidentTypeInappropriate  IncidentType = "inappropriate"
-	IncidentTypeImpersonation  IncidentType = "impersonation"
+	IncidentTypeHarassment    IncidentType = "harassment"
+	IncidentTypeInappropriate IncidentType = "inappropriate"
+	IncidentTypeImpersonation IncidentType = "impersonation"
 	IncidentTypeScam          IncidentType = "scam"
 	IncidentTypeEmergency     IncidentType = "emergency"
 	IncidentTypeOther         IncidentType = "other"
 )
 
+// Valid reports whether t is a known incident type
+func (t IncidentType) Valid() bool {
+	switch t {
+	case IncidentTypeHarassment, IncidentTypeInappropriate, IncidentTypeImpersonation,
+		IncidentTypeScam, IncidentTypeEmergency, IncidentTypeOther:
+		return true
+	}
+	return false
+}
+
+// DefaultPriority is the queue priority a new report of type t starts with
+func DefaultPriority(t IncidentType) int {
+	switch t {
+	case IncidentTypeEmergency:
+		return 100
+	case IncidentTypeHarassment:
+		return 50
+	case IncidentTypeScam:
+		return 40
+	case IncidentTypeImpersonation:
+		return 30
+	case IncidentTypeInappropriate:
+		return 20
+	}
+	return 10
+}
+
 type IncidentStatus string
 
 const (
-	IncidentStatusPending    IncidentStatus = "pending"
-	IncidentStatusReviewing  IncidentStatus = "reviewing"
-	IncidentStatusResolved   IncidentStatus = "resolved"
-	IncidentStatusDismissed  IncidentStatus = "dismissed"
+	IncidentStatusPending   IncidentStatus = "pending"
+	IncidentStatusReviewing IncidentStatus = "reviewing"
+	IncidentStatusResolved  IncidentStatus = "resolved"
+	IncidentStatusDismissed IncidentStatus = "dismissed"
 )
 
+// Valid reports whether s is a known incident status
+func (s IncidentStatus) Valid() bool {
+	switch s {
+	case IncidentStatusPending, IncidentStatusReviewing, IncidentStatusResolved, IncidentStatusDismissed:
+		return true
+	}
+	return false
+}
+
+// reportTransitions lists the statuses each status may move to through the
+// normal review flow. Reopening a closed report is a separate, explicit action.
+var reportTransitions = map[IncidentStatus][]IncidentStatus{
+	IncidentStatusPending:   {IncidentStatusReviewing},
+	IncidentStatusReviewing: {IncidentStatusResolved, IncidentStatusDismissed},
+}
+
+// CanTransitionTo reports whether a report in status s may move to next
+func (s IncidentStatus) CanTransitionTo(next IncidentStatus) bool {
+	for _, allowed := range reportTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Closed reports whether s ends the review; closed reports can only be reopened
+func (s IncidentStatus) Closed() bool {
+	return s == IncidentStatusResolved || s == IncidentStatusDismissed
+}
+
+// ReportOutcome is what a reporter is told about their report. Outcomes say
+// whether action was taken, never which sanction was applied.
+type ReportOutcome string
+
+const (
+	ReportOutcomeReceived    ReportOutcome = "received"
+	ReportOutcomeUnderReview ReportOutcome = "under_review"
+	ReportOutcomeActionTaken ReportOutcome = "action_taken"
+	ReportOutcomeResolved    ReportOutcome = "resolved"
+	ReportOutcomeNoAction    ReportOutcome = "no_action"
+)
+
+// OutcomeFor maps a report status to the outcome shown to its reporter.
+// A resolved report only counts as action taken when sanctioned is set,
+// that is when a moderator sanctioned the reported user over it.
+func OutcomeFor(status IncidentStatus, sanctioned bool) ReportOutcome {
+	switch status {
+	case IncidentStatusReviewing:
+		return ReportOutcomeUnderReview
+	case IncidentStatusResolved:
+		if !sanctioned {
+			return ReportOutcomeResolved
+		}
+		return ReportOutcomeActionTaken
+	case IncidentStatusDismissed:
+		return ReportOutcomeNoAction
+	}
+	return ReportOutcomeReceived
+}
+
+// Message is the text shown to the reporter for the outcome
+func (o ReportOutcome) Message() string {
+	switch o {
+	case ReportOutcomeUnderReview:
+		return "Our safety team is reviewing your report."
+	case ReportOutcomeActionTaken:
+		return "Thanks for your report. We reviewed it and took action. To protect everyone's privacy, we don't share the details."
+	case ReportOutcomeResolved:
+		return "Thanks for your report. We reviewed it and it has been resolved."
+	case ReportOutcomeNoAction:
+		return "Thanks for your report. We reviewed it and didn't find a violation of our community guidelines."
+	}
+	return "We received your report and will review it soon."
+}
+
+// OpenStatuses are the statuses of reports still awaiting a decision
+var OpenStatuses = []IncidentStatus{IncidentStatusPending, IncidentStatusReviewing}
+
+// SLATier sets how long reports of at least MinPriority may wait for review
+type SLATier struct {
+	MinPriority int
+	Within      time.Duration
+}
+
+// ParseSLATiers reads tiers written as "priority=duration", e.g. "90=1h".
+// The result is ordered from the highest priority down.
+func ParseSLATiers(specs []string) ([]SLATier, error) {
+	tiers := make([]SLATier, 0, len(specs))
+	for _, spec := range specs {
+		p, d, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("SLA tier %q must be priority=duration", spec)
+		}
+		priority, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return nil, fmt.Errorf("SLA tier %q: invalid priority", spec)
+		}
+		within, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || within <= 0 {
+			return nil, fmt.Errorf("SLA tier %q: invalid duration", spec)
+		}
+		tiers = append(tiers, SLATier{MinPriority: priority, Within: within})
+	}
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].MinPriority > tiers[j].MinPriority
+	})
+	return tiers, nil
+}
+
+// SLAWindow returns how long a report of the given priority may wait for
+// review, and false if no tier covers the priority
+func SLAWindow(tiers []SLATier, priority int) (time.Duration, bool) {
+	for _, tier := range tiers {
+		if priority >= tier.MinPriority {
+			return tier.Within, true
+		}
+	}
+	return 0, false
+}
+
+// SLADeadline returns when a report of the given priority filed at created
+// must be reviewed by, or nil if no tier covers the priority
+func SLADeadline(tiers []SLATier, priority int, created time.Time) *time.Time {
+	within, ok := SLAWindow(tiers, priority)
+	if !ok {
+		return nil
+	}
+	deadline := created.Add(within)
+	return &deadline
+}
+
 // SafetyReport represents a user-submitted incident report
 type SafetyReport struct {
 	ID          uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid"`
@@ -35,18 +198,207 @@ type SafetyReport struct {
 	Description string         `json:"description"`
 	Evidence    []Evidence     `json:"evidence" gorm:"foreignKey:ReportID"`
 	Status      IncidentStatus `json:"status" gorm:"not null;default:'pending'"`
+	Priority    int            `json:"priority" gorm:"not null;default:0"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	ResolvedAt  *time.Time     `json:"resolved_at"`
+	// SLADeadline is when review should be finished; OverdueAt is set once
+	// the SLA checker finds the report still open after it
+	SLADeadline *time.Time `json:"sla_deadline"`
+	OverdueAt   *time.Time `json:"overdue_at"`
+	// ChainLength and ChainHead track the end of the report's hash chain, so
+	// entries removed from the tail are detected too
+	ChainLength int64  `json:"-" gorm:"not null;default:0"`
+	ChainHead   string `json:"-" gorm:"default:null"`
+	// Shadowed marks reports filed by a shadow-restricted reporter. They are
+	// queued at the lowest priority and ignored by escalation rules; the
+	// field is never serialised so the reporter cannot tell.
+	Shadowed bool `json:"-" gorm:"not null;default:false"`
+	// AssigneeID is the moderator responsible for the report. ClaimedBy holds
+	// the review lock until ClaimExpiresAt; while the claim is live no other
+	// moderator may change the report's status.
+	AssigneeID     *uuid.UUID `json:"assignee_id"`
+	AssignedAt     *time.Time `json:"assigned_at"`
+	ClaimedBy      *uuid.UUID `json:"claimed_by"`
+	ClaimExpiresAt *time.Time `json:"claim_expires_at"`
+
+	// ReporterCredibility is filled in for moderators
+	ReporterCredibility *ReporterCredibility `json:"reporter_credibility,omitempty" gorm:"-"`
+	// InReviewBy names the holder of a live claim for moderators
+	InReviewBy *ReportReviewer `json:"in_review_by,omitempty" gorm:"-"`
+}
+
+// ClaimHolder returns who holds a live claim on the report at now, or nil
+func (r *SafetyReport) ClaimHolder(now time.Time) *uuid.UUID {
+	if r.ClaimedBy == nil || r.ClaimExpiresAt == nil || !r.ClaimExpiresAt.After(now) {
+		return nil
+	}
+	return r.ClaimedBy
+}
+
+// ReportReviewer is the moderator holding a report's review claim
+type ReportReviewer struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ReporterCredibility summarises how a reporter's past reports were decided.
+// Score is the smoothed share of decided reports that were upheld: 0.5 with
+// no history, falling towards 0 as more reports are dismissed.
+type ReporterCredibility struct {
+	ReporterID uuid.UUID `json:"reporter_id"`
+	Filed      int64     `json:"filed"`
+	Resolved   int64     `json:"resolved"`
+	Dismissed  int64     `json:"dismissed"`
+	Score      float64   `json:"score"`
+}
+
+// NewReporterCredibility computes the score from a reporter's report counts
+func NewReporterCredibility(reporterID uuid.UUID, filed, resolved, dismissed int64) *ReporterCredibility {
+	return &ReporterCredibility{
+		ReporterID: reporterID,
+		Filed:      filed,
+		Resolved:   resolved,
+		Dismissed:  dismissed,
+		Score:      float64(resolved+1) / float64(resolved+dismissed+2),
+	}
+}
+
+// ChainAnchor is the content hashed into the first link of the report's
+// chain, tying the chain to the report as it was filed
+func (r *SafetyReport) ChainAnchor() []string {
+	return []string{
+		"safety_report",
+		r.ID.String(),
+		r.ReporterID.String(),
+		r.ReportedID.String(),
+		string(r.Type),
+		r.Description,
+		chainTime(r.CreatedAt),
+	}
+}
+
+type NotificationKind string
+
+const (
+	// NotificationOutcome reports a status change
+	NotificationOutcome NotificationKind = "outcome"
+	// NotificationResponse carries a message a moderator sent from a
+	// response template
+	NotificationResponse NotificationKind = "response"
+)
+
+// ReportNotification tells a reporter the outcome of their report, or
+// carries a moderator's response. It is pushed over the websocket and kept
+// until read for offline users.
+type ReportNotification struct {
+	ID        uuid.UUID        `json:"id" gorm:"primaryKey;type:uuid"`
+	UserID    uuid.UUID        `json:"-" gorm:"type:uuid;not null"`
+	ReportID  uuid.UUID        `json:"report_id" gorm:"type:uuid;not null"`
+	Kind      NotificationKind `json:"kind" gorm:"not null;default:'outcome'"`
+	Outcome   ReportOutcome    `json:"outcome" gorm:"not null"`
+	Message   string           `json:"message" gorm:"not null"`
+	CreatedAt time.Time        `json:"created_at"`
+	ReadAt    *time.Time       `json:"read_at"`
+	// TemplateID and SentBy identify a response's template and sender for
+	// staff; reporters never see who answered them
+	TemplateID *uuid.UUID `json:"-" gorm:"type:uuid"`
+	SentBy     *uuid.UUID `json:"-" gorm:"type:uuid"`
+}
+
+// ChainLink places a report event or evidence item in its report's
+// tamper-evident hash chain. Hash covers the entry's content and PrevHash,
+// the hash of the entry before it. Rows written before the chain existed
+// have no link.
+type ChainLink struct {
+	ChainSeq *int64 `json:"chain_seq,omitempty"`
+	PrevHash string `json:"prev_hash,omitempty" gorm:"default:null"`
+	Hash     string `json:"hash,omitempty" gorm:"default:null"`
+}
+
+// ReportEvent records a change to a safety report's status. The first event
+// of every report has no FromStatus and is written when the report is filed.
+// Events with an Action record something done about the report, such as an
+// appeal step, and leave its status unchanged.
+type ReportEvent struct {
+	ID         uuid.UUID       `json:"id" gorm:"primaryKey;type:uuid"`
+	ReportID   uuid.UUID       `json:"report_id" gorm:"type:uuid;not null"`
+	ActorID    uuid.UUID       `json:"actor_id" gorm:"type:uuid;not null"`
+	FromStatus *IncidentStatus `json:"from_status"`
+	ToStatus   IncidentStatus  `json:"to_status" gorm:"not null"`
+	Action     string          `json:"action,omitempty" gorm:"default:null"`
+	Note       string          `json:"note"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ChainLink
 }
 
-// Evidence represents supporting evidence for a safety report
+// ChainContent is the part of the event covered by its chain hash
+func (e *ReportEvent) ChainContent() []string {
+	from := ""
+	if e.FromStatus != nil {
+		from = string(*e.FromStatus)
+	}
+	content := []string{
+		"report_event",
+		chainSeq(e.ChainSeq),
+		e.ID.String(),
+		e.ReportID.String(),
+		e.ActorID.String(),
+		from,
+		string(e.ToStatus),
+		e.Note,
+		chainTime(e.CreatedAt),
+	}
+	// Appended only when set so events written before actions existed
+	// keep their hashes
+	if e.Action != "" {
+		content = append(content, e.Action)
+	}
+	return content
+}
+
+// Evidence represents supporting evidence for a safety report or an
+// emergency alert. Linked
+// evidence only has a URL; uploaded evidence is kept in the blob store under
+// StorageKey and is downloaded through a signed DownloadURL.
 type Evidence struct {
-	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid"`
-	ReportID  uuid.UUID `json:"report_id" gorm:"type:uuid;not null"`
-	Type      string    `json:"type" gorm:"not null"` // e.g., "image", "chat_log", "screenshot"
-	URL       string    `json:"url" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	ReportID    *uuid.UUID `json:"report_id,omitempty" gorm:"type:uuid"`
+	AlertID     *uuid.UUID `json:"alert_id,omitempty" gorm:"type:uuid"`
+	Type        string     `json:"type" gorm:"not null"` // e.g., "image", "chat_log", "screenshot"
+	URL         string     `json:"url,omitempty"`
+	UploaderID  *uuid.UUID `json:"uploader_id,omitempty" gorm:"type:uuid"`
+	FileName    string     `json:"file_name,omitempty"`
+	ContentType string     `json:"content_type,omitempty"`
+	Size        int64      `json:"size,omitempty"`
+	SHA256      string     `json:"sha256,omitempty" gorm:"column:sha256"`
+	StorageKey  string     `json:"-" gorm:"default:null"`
+	CreatedAt   time.Time  `json:"created_at"`
+	// Only report evidence is chained; alert evidence has no link
+	ChainLink
+
+	// DownloadURL is filled in per request for the requesting user
+	DownloadURL string `json:"download_url,omitempty" gorm:"-"`
+}
+
+// ChainContent is the part of the evidence covered by its chain hash. The
+// file itself is covered through its SHA-256.
+func (e *Evidence) ChainContent() []string {
+	return []string{
+		"evidence",
+		chainSeq(e.ChainSeq),
+		e.ID.String(),
+		optionalID(e.ReportID),
+		e.Type,
+		e.URL,
+		optionalID(e.UploaderID),
+		e.FileName,
+		e.ContentType,
+		strconv.FormatInt(e.Size, 10),
+		e.SHA256,
+		chainTime(e.CreatedAt),
+	}
 }
 
 // EmergencyContact represents a user's emergency contact
@@ -64,23 +416,23 @@ type EmergencyContact struct {
 
 // UserBlock represents a user blocking another user
 type UserBlock struct {
-	ID          uuid.UUID `json:"id" gorm:"primaryKey;type:uuid"`
-	BlockerID   uuid.UUID `json:"blocker_id" gorm:"type:uuid;not null"`
-	BlockedID   uuid.UUID `json:"blocked_id" gorm:"type:uuid;not null"`
-	Reason      string    `json:"reason"`
-	CreatedAt   time.Time `json:"created_at"`
-	ExpiresAt   *time.Time `json:"expires_at"` // Optional expiration for temporary blocks
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	BlockerID uuid.UUID  `json:"blocker_id" gorm:"type:uuid;not null"`
+	BlockedID uuid.UUID  `json:"blocked_id" gorm:"type:uuid;not null"`
+	Reason    string     `json:"reason"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"` // Optional expiration for temporary blocks
 }
 
 // EmergencyAlert represents a real-time emergency alert
 type EmergencyAlert struct {
-	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid"`
-	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
-	Type      string    `json:"type" gorm:"not null"`
-	Location  *Location `json:"location" gorm:"embedded"`
-	Status    string    `json:"status" gorm:"not null;default:'active'"`
-	Message   string    `json:"message"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	Type       string     `json:"type" gorm:"not null"`
+	Location   *Location  `json:"location" gorm:"embedded"`
+	Status     string     `json:"status" gorm:"not null;default:'active'"`
+	Message    string     `json:"message"`
+	CreatedAt  time.Time  `json:"created_at"`
 	ResolvedAt *time.Time `json:"resolved_at"`
 }
 
@@ -90,3 +442,23 @@ type Location struct {
 	Longitude float64 `json:"longitude"`
 	Accuracy  float32 `json:"accuracy"`
 }
+
+// chainTime formats t at the microsecond precision Postgres stores, so a
+// hash computed before insert matches one computed after reading back
+func chainTime(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
+
+func chainSeq(seq *int64) string {
+	if seq == nil {
+		return ""
+	}
+	return strconv.FormatInt(*seq, 10)
+}
+
+func optionalID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIncidentStatusCanTransitionTo(t *testing.T) {
+	statuses := []IncidentStatus{
+		IncidentStatusPending,
+		IncidentStatusReviewing,
+		IncidentStatusResolved,
+		IncidentStatusDismissed,
+	}
+	allowed := map[[2]IncidentStatus]bool{
+		{IncidentStatusPending, IncidentStatusReviewing}:   true,
+		{IncidentStatusReviewing, IncidentStatusResolved}:  true,
+		{IncidentStatusReviewing, IncidentStatusDismissed}: true,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := allowed[[2]IncidentStatus{from, to}]
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", from, to, got, want)
+			}
+		}
+		if from.CanTransitionTo("archived") {
+			t.Errorf("%s.CanTransitionTo(archived) = true, want false", from)
+		}
+	}
+	if IncidentStatus("archived").CanTransitionTo(IncidentStatusReviewing) {
+		t.Error("unknown status may transition")
+	}
+
+	for _, s := range statuses {
+		want := s == IncidentStatusResolved || s == IncidentStatusDismissed
+		if got := s.Closed(); got != want {
+			t.Errorf("%s.Closed() = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestOutcomeFor(t *testing.T) {
+	tests := []struct {
+		status     IncidentStatus
+		sanctioned bool
+		want       ReportOutcome
+	}{
+		{IncidentStatusPending, false, ReportOutcomeReceived},
+		{IncidentStatusReviewing, false, ReportOutcomeUnderReview},
+		{IncidentStatusResolved, false, ReportOutcomeResolved},
+		{IncidentStatusResolved, true, ReportOutcomeActionTaken},
+		{IncidentStatusDismissed, false, ReportOutcomeNoAction},
+	}
+	for _, tt := range tests {
+		if got := OutcomeFor(tt.status, tt.sanctioned); got != tt.want {
+			t.Errorf("OutcomeFor(%s, %v) = %s, want %s", tt.status, tt.sanctioned, got, tt.want)
+		}
+	}
+}
+
+func TestParseSLATiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		want    []SLATier
+		wantErr bool
+	}{
+		{name: "none", specs: nil, want: []SLATier{}},
+		{
+			name:  "sorted highest first",
+			specs: []string{"0=72h", "100=15m", " 50 = 4h "},
+			want: []SLATier{
+				{MinPriority: 100, Within: 15 * time.Minute},
+				{MinPriority: 50, Within: 4 * time.Hour},
+				{MinPriority: 0, Within: 72 * time.Hour},
+			},
+		},
+		{name: "missing separator", specs: []string{"90:1h"}, wantErr: true},
+		{name: "bad priority", specs: []string{"high=1h"}, wantErr: true},
+		{name: "bad duration", specs: []string{"90=soon"}, wantErr: true},
+		{name: "zero duration", specs: []string{"90=0s"}, wantErr: true},
+		{name: "negative duration", specs: []string{"90=-1h"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSLATiers(tt.specs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSLATiers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseSLATiers() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("tier %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSLADeadline(t *testing.T) {
+	tiers, err := ParseSLATiers([]string{"100=15m", "50=4h", "10=24h"})
+	if err != nil {
+		t.Fatalf("ParseSLATiers: %v", err)
+	}
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		priority int
+		want     time.Duration
+		covered  bool
+	}{
+		{100, 15 * time.Minute, true},
+		{99, 4 * time.Hour, true},
+		{50, 4 * time.Hour, true},
+		{49, 24 * time.Hour, true},
+		{10, 24 * time.Hour, true},
+		{9, 0, false},
+	}
+	for _, tt := range tests {
+		within, ok := SLAWindow(tiers, tt.priority)
+		if ok != tt.covered || within != tt.want {
+			t.Errorf("SLAWindow(%d) = %v, %v, want %v, %v", tt.priority, within, ok, tt.want, tt.covered)
+		}
+
+		deadline := SLADeadline(tiers, tt.priority, created)
+		switch {
+		case !tt.covered && deadline != nil:
+			t.Errorf("SLADeadline(%d) = %v, want nil", tt.priority, deadline)
+		case tt.covered && (deadline == nil || !deadline.Equal(created.Add(tt.want))):
+			t.Errorf("SLADeadline(%d) = %v, want %v", tt.priority, deadline, created.Add(tt.want))
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SanctionType string
+
+const (
+	// SanctionWarning only notifies the user
+	SanctionWarning SanctionType = "warning"
+	// SanctionSuspension locks the user out until it expires or is lifted
+	SanctionSuspension SanctionType = "suspension"
+	// SanctionBan locks the user out permanently unless lifted
+	SanctionBan SanctionType = "ban"
+	// SanctionShadowRestriction quietly limits the user without telling them
+	SanctionShadowRestriction SanctionType = "shadow_restriction"
+)
+
+// Valid reports whether t is a known sanction type
+func (t SanctionType) Valid() bool {
+	switch t {
+	case SanctionWarning, SanctionSuspension, SanctionBan, SanctionShadowRestriction:
+		return true
+	}
+	return false
+}
+
+// LocksOut reports whether a sanction of type t stops the user using the API
+func (t SanctionType) LocksOut() bool {
+	return t == SanctionSuspension || t == SanctionBan
+}
+
+// Severity orders sanction types from warning (lowest) to ban
+func (t SanctionType) Severity() int {
+	switch t {
+	case SanctionWarning:
+		return 1
+	case SanctionShadowRestriction:
+		return 2
+	case SanctionSuspension:
+		return 3
+	case SanctionBan:
+		return 4
+	}
+	return 0
+}
+
+// UserSanction restricts a user's account. Sanctions without an issuer were
+// applied automatically and are pending review until a moderator confirms
+// them, becoming their issuer, or lifts them.
+type UserSanction struct {
+	ID             uuid.UUID    `json:"id" gorm:"primaryKey;type:uuid"`
+	UserID         uuid.UUID    `json:"user_id" gorm:"type:uuid;not null"`
+	Type           SanctionType `json:"type" gorm:"not null"`
+	Reason         string       `json:"reason" gorm:"not null"`
+	ReportID       *uuid.UUID   `json:"report_id,omitempty" gorm:"type:uuid"`
+	EscalationRule string       `json:"escalation_rule,omitempty" gorm:"default:null"`
+	IssuedBy       *uuid.UUID   `json:"issued_by,omitempty" gorm:"type:uuid"`
+	PendingReview  bool         `json:"pending_review" gorm:"not null;default:false"`
+	CreatedAt      time.Time    `json:"created_at"`
+	ExpiresAt      *time.Time   `json:"expires_at"`
+	LiftedAt       *time.Time   `json:"lifted_at"`
+	LiftedBy       *uuid.UUID   `json:"lifted_by,omitempty" gorm:"type:uuid"`
+	LiftReason     string       `json:"lift_reason,omitempty" gorm:"default:null"`
+}
+
+// Active reports whether the sanction is in force at t
+func (s *UserSanction) Active(t time.Time) bool {
+	if s.LiftedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || t.Before(*s.ExpiresAt)
+}
+
+// SanctionEvent records an appeal step taken on a sanction
+type SanctionEvent struct {
+	ID         uuid.UUID `json:"id" gorm:"primaryKey;type:uuid"`
+	SanctionID uuid.UUID `json:"sanction_id" gorm:"type:uuid;not null"`
+	ActorID    uuid.UUID `json:"actor_id" gorm:"type:uuid;not null"`
+	Action     string    `json:"action" gorm:"not null"`
+	Note       string    `json:"note,omitempty" gorm:"default:null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserSanctionActive(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		sanction UserSanction
+		want     bool
+	}{
+		{"open-ended", UserSanction{}, true},
+		{"expires later", UserSanction{ExpiresAt: &future}, true},
+		{"expired", UserSanction{ExpiresAt: &past}, false},
+		{"expires exactly now", UserSanction{ExpiresAt: &now}, false},
+		{"lifted", UserSanction{LiftedAt: &past}, false},
+		{"lifted before expiry", UserSanction{ExpiresAt: &future, LiftedAt: &past}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sanction.Active(now); got != tt.want {
+				t.Errorf("Active() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanctionType(t *testing.T) {
+	tests := []struct {
+		typ      SanctionType
+		valid    bool
+		locksOut bool
+		severity int
+	}{
+		{SanctionWarning, true, false, 1},
+		{SanctionShadowRestriction, true, false, 2},
+		{SanctionSuspension, true, true, 3},
+		{SanctionBan, true, true, 4},
+		{SanctionType("mute"), false, false, 0},
+		{SanctionType(""), false, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.typ), func(t *testing.T) {
+			if got := tt.typ.Valid(); got != tt.valid {
+				t.Errorf("Valid() = %v, want %v", got, tt.valid)
+			}
+			if got := tt.typ.LocksOut(); got != tt.locksOut {
+				t.Errorf("LocksOut() = %v, want %v", got, tt.locksOut)
+			}
+			if got := tt.typ.Severity(); got != tt.severity {
+				t.Errorf("Severity() = %d, want %d", got, tt.severity)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResumableUpload tracks a tus upload that becomes Evidence once every byte
+// has arrived. Exactly one of ReportID and AlertID is set.
+type ResumableUpload struct {
+	ID          uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	ReportID    *uuid.UUID `json:"report_id,omitempty" gorm:"type:uuid"`
+	AlertID     *uuid.UUID `json:"alert_id,omitempty" gorm:"type:uuid"`
+	Length      int64      `json:"length" gorm:"not null"`
+	Offset      int64      `json:"offset" gorm:"not null;default:0"`
+	FileName    string     `json:"file_name"`
+	Type        string     `json:"type"`
+	ContentType string     `json:"content_type"`
+	EvidenceID  *uuid.UUID `json:"evidence_id,omitempty" gorm:"type:uuid"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// UploadChunk is one stored PATCH body of a resumable upload
+type UploadChunk struct {
+	UploadID   uuid.UUID `gorm:"primaryKey;type:uuid"`
+	Offset     int64     `gorm:"primaryKey"`
+	Size       int64     `gorm:"not null"`
+	StorageKey string    `gorm:"not null"`
+}
+
+// TableName keeps the chunk table name next to resumable_uploads
+func (UploadChunk) TableName() string {
+	return "resumable_upload_chunks"
+}
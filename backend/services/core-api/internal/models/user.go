@@ -1,26 +1,47 @@
 package models
 
 import (
-    "time"
-    "github.com/google/uuid"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+	RoleSupport   Role = "support"
 )
 
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	switch r {
+	case RoleUser, RoleModerator, RoleAdmin, RoleSupport:
+		return true
+	}
+	return false
+}
+
 type User struct {
-    ID             uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
-    Email          string     `json:"email" gorm:"unique;not null"`
-    Username       string     `json:"username" gorm:"unique;not null"`
-    HashedPassword string     `json:"-" gorm:"not null"`
-    FirstName      string     `json:"firstName"`
-    LastName       string     `json:"lastName"`
-    Bio           string     `json:"bio"`
-    Interests     []string   `json:"interests" gorm:"type:text[]"`
-    Location      *Location  `json:"location,omitempty" gorm:"embedded"`
-    CreatedAt     time.Time  `json:"createdAt"`
-    UpdatedAt     time.Time  `json:"updatedAt"`
+	ID             uuid.UUID     `json:"id" gorm:"primaryKey;type:uuid"`
+	Email          string        `json:"email" gorm:"unique;not null"`
+	Username       string        `json:"username" gorm:"unique;not null"`
+	HashedPassword string        `json:"-" gorm:"not null"`
+	FirstName      string        `json:"firstName"`
+	LastName       string        `json:"lastName"`
+	Role           Role          `json:"role" gorm:"not null;default:'user'"`
+	Bio            string        `json:"bio"`
+	Interests      []string      `json:"interests" gorm:"type:text[]"`
+	Location       *UserLocation `json:"location,omitempty" gorm:"embedded"`
+	CreatedAt      time.Time     `json:"createdAt"`
+	UpdatedAt      time.Time     `json:"updatedAt"`
 }
 
-type Location struct {
-    Latitude  float64 `json:"latitude"`
-    Longitude float64 `json:"longitude"`
-    LastUpdate time.Time `json:"lastUpdate"`
+// UserLocation is the last known position of a user
+type UserLocation struct {
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	LastUpdate time.Time `json:"lastUpdate"`
 }
@@ -1,29 +1,312 @@
 package server
 
 import (
-	"github.com/gin-gonic/gin"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"disco/core-api/internal/auth"
 	"disco/core-api/internal/config"
+	"disco/core-api/internal/database"
+	"disco/core-api/internal/escalation"
+	"disco/core-api/internal/export"
+	"disco/core-api/internal/handlers"
+	"disco/core-api/internal/metrics"
+	"disco/core-api/internal/middleware"
+	"disco/core-api/internal/migrate"
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/services"
+	"disco/core-api/internal/storage"
+	"disco/core-api/internal/websocket"
+	"disco/core-api/migrations"
+	"disco/pkg/cors"
+	"disco/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"gorm.io/gorm"
 )
 
+// serviceName identifies core-api in traces
+const serviceName = "core-api"
+
+// uploadJanitorInterval is how often abandoned resumable uploads are purged
+const uploadJanitorInterval = time.Hour
+
 type Server struct {
 	router *gin.Engine
 	config *config.Config
+	db     *gorm.DB
+	redis  *redis.Client
+	hub    *websocket.Hub
+	health *handlers.HealthHandler
+	cors   *cors.Policy
+	store  storage.BlobStore
+	rules  []escalation.Rule
+	sla    []models.SLATier
+	// exportKey signs law-enforcement exports; nil disables them
+	exportKey ed25519.PrivateKey
+	// safety is kept for the SLA checker started in Start
+	safety *services.SafetyService
+	// evidence is kept for the upload janitor started in Start
+	evidence *services.EvidenceService
+	// shutdownTracing flushes buffered spans
+	shutdownTracing func(context.Context) error
 }
 
-func New(cfg *config.Config) *Server {
-	return &Server{
-		router: gin.Default(),
-		config: cfg,
+// New connects to the database and wires services and handlers into the router
+func New(cfg *config.Config) (*Server, error) {
+	if !cfg.Debug {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), serviceName, cfg.TracingOptions())
+	if err != nil {
+		return nil, fmt.Errorf("set up tracing: %w", err)
 	}
+
+	db, err := database.Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MigrateOnStart {
+		if err := runMigrations(db); err != nil {
+			return nil, err
+		}
+	}
+
+	rdb, err := database.ConnectRedis(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	rdb.AddHook(metrics.Redis)
+	rdb.AddHook(tracing.NewRedisHook())
+
+	store, err := storage.Open(context.Background(), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("open evidence storage: %w", err)
+	}
+
+	rules, err := escalation.Load(cfg.EscalationRulesFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) > 0 {
+		slog.Info("loaded escalation rules", slog.Int("count", len(rules)))
+	}
+
+	slaTiers, err := cfg.SLATiers()
+	if err != nil {
+		return nil, err
+	}
+
+	exportKey, err := export.LoadPrivateKey(cfg.ExportSigningKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load export signing key: %w", err)
+	}
+
+	corsPolicy, err := cfg.CORSPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		router:          gin.New(),
+		config:          cfg,
+		db:              db,
+		redis:           rdb,
+		hub:             websocket.NewHub(corsPolicy.CheckOrigin),
+		cors:            corsPolicy,
+		store:           store,
+		rules:           rules,
+		sla:             slaTiers,
+		exportKey:       exportKey,
+		health:          handlers.NewHealthHandler(db, rdb),
+		shutdownTracing: shutdownTracing,
+	}
+	s.setupRoutes()
+
+	return s, nil
 }
 
-func (s *Server) Start() error {
-	// Add routes here
-	s.router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "ok",
-		})
+// setupRoutes mounts all handlers under the versioned API group
+func (s *Server) setupRoutes() {
+	s.router.Use(gin.Recovery())
+	s.router.Use(otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+		// Probes and scrapes would otherwise dominate the traces
+		switch r.URL.Path {
+		case "/health", "/livez", "/readyz", "/metrics":
+			return false
+		}
+		return true
+	})))
+	s.router.Use(middleware.RequestID())
+	s.router.Use(middleware.Logger())
+	s.router.Use(middleware.Metrics())
+	s.router.Use(middleware.Cors(s.cors))
+	s.router.Use(middleware.BodyLimit(s.config.MaxBodyBytes, handlers.EvidenceUploadRoute, handlers.TusChunkRoute))
+
+	s.health.RegisterRoutes(&s.router.RouterGroup)
+	s.router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	tokens := auth.NewTokenService(s.config.JWTSecret, s.redis, s.config.AccessTokenTTL, s.config.RefreshTokenTTL)
+
+	authService := services.NewAuthService(s.db, tokens)
+	s.safety = services.NewSafetyService(s.db, s.hub, services.ReportLimits{
+		DuplicateWindow: s.config.ReportDuplicateWindow,
+		PerTargetLimit:  s.config.ReportPerTargetLimit,
+		RateLimit:       s.config.ReportRateLimit,
+		RateWindow:      s.config.ReportRateWindow,
+	}, s.rules, s.config.AutoSuspensionDuration, s.sla)
+	s.safety.SetAssignment(services.ReportAssignment{
+		Strategy: services.AssignmentStrategy(s.config.ReportAssignment),
+		ClaimTTL: s.config.ReportClaimTTL,
+	})
+	s.safety.SetRelay(s.redis)
+	userService := services.NewUserService(s.db, tokens)
+	sanctionService := services.NewSanctionService(s.db, s.config.SanctionCacheTTL)
+	s.safety.SetSanctions(sanctionService)
+	appealService := services.NewAppealService(s.db, s.hub, sanctionService)
+	s.evidence = services.NewEvidenceService(s.db, s.store, storage.NewSigner(s.config.JWTSecret), services.EvidenceLimits{
+		MaxBytes:          s.config.EvidenceMaxBytes,
+		AllowedTypes:      s.config.EvidenceAllowedTypes,
+		URLTTL:            s.config.EvidenceURLTTL,
+		ResumableMaxBytes: s.config.ResumableMaxBytes,
+		ResumableTTL:      s.config.ResumableUploadTTL,
 	})
 
-	return s.router.Run(":8080")
+	authHandler := handlers.NewAuthHandler(authService)
+	evidenceHandler := handlers.NewEvidenceHandler(s.evidence)
+	tusHandler := handlers.NewTusHandler(s.evidence, s.config.ResumableChunkTimeout)
+
+	api := s.router.Group("/api/v1")
+	authHandler.RegisterRoutes(api)
+	evidenceHandler.RegisterPublicRoutes(api)
+	tusHandler.RegisterPublicRoutes(api)
+
+	protected := api.Group("")
+	protected.Use(middleware.Auth(tokens))
+	protected.Use(middleware.Sanctions(sanctionService, handlers.LockoutExemptRoutes...))
+	authHandler.RegisterProtectedRoutes(protected)
+	handlers.NewSafetyHandler(s.safety).RegisterRoutes(protected)
+	handlers.NewSanctionHandler(sanctionService).RegisterRoutes(protected)
+	handlers.NewAppealHandler(appealService).RegisterRoutes(protected)
+	handlers.NewCaseHandler(services.NewCaseService(s.db, s.config.CaseMergeWindow)).RegisterRoutes(protected)
+	handlers.NewResponseHandler(services.NewResponseService(s.db, s.hub)).RegisterRoutes(protected)
+	evidenceHandler.RegisterRoutes(protected)
+	tusHandler.RegisterRoutes(protected)
+	handlers.NewWebsocketHandler(s.hub).RegisterRoutes(protected)
+	handlers.NewAdminHandler(userService).RegisterRoutes(protected)
+	handlers.NewExportHandler(services.NewExportService(s.db, s.store, s.safety, s.exportKey)).RegisterRoutes(protected)
+}
+
+// Start serves HTTP until SIGINT or SIGTERM. It then reports draining for
+// DrainDelay, drains in-flight requests and websocket clients, and stops the
+// background jobs before closing the database and Redis connections
+func (s *Server) Start() error {
+	go s.hub.Run()
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go s.evidence.RunUploadJanitor(jobsCtx, uploadJanitorInterval)
+	go s.safety.RunSLAChecker(jobsCtx, s.config.ReportSLACheckInterval)
+	go s.safety.RunModeratorRelay(jobsCtx)
+
+	srv := &http.Server{
+		Addr:              s.config.ServerAddr,
+		Handler:           s.router,
+		ReadTimeout:       s.config.ReadTimeout,
+		ReadHeaderTimeout: s.config.ReadTimeout,
+		WriteTimeout:      s.config.WriteTimeout,
+		IdleTimeout:       s.config.IdleTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("core api starting", slog.String("addr", s.config.ServerAddr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case err := <-serveErr:
+		stopJobs()
+		s.close()
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down server")
+	s.health.SetDraining()
+	time.Sleep(s.config.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("http server shutdown failed", slog.Any("error", err))
+	}
+	if err := s.hub.Shutdown(shutdownCtx); err != nil {
+		slog.Error("websocket hub shutdown failed", slog.Any("error", err))
+	}
+	stopJobs()
+	s.close()
+
+	return nil
+}
+
+// close flushes traces and releases the database and Redis connections
+func (s *Server) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+	if err := s.shutdownTracing(ctx); err != nil {
+		slog.Error("tracing shutdown failed", slog.Any("error", err))
+	}
+
+	if sqlDB, err := s.db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("database close failed", slog.Any("error", err))
+		}
+	}
+	if err := s.redis.Close(); err != nil {
+		slog.Error("redis close failed", slog.Any("error", err))
+	}
+}
+
+// runMigrations applies pending migrations. The migrator serialises replicas
+// through a Postgres advisory lock, so every instance can call this on startup.
+func runMigrations(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	migrator, err := migrate.New(sqlDB, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		return fmt.Errorf("run migrations: %w", err)
+	}
+	if applied > 0 {
+		slog.Info("applied migrations", slog.Int("count", applied))
+	}
+
+	return nil
 }
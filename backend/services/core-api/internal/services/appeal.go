@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/websocket"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAppealNotFound        = errors.New("appeal not found")
+	ErrAppealExists          = errors.New("sanction already appealed")
+	ErrAppealDecided         = errors.New("appeal already decided")
+	ErrSanctionNotInForce    = errors.New("sanction is no longer in force")
+	ErrInvalidAppealDecision = errors.New("invalid appeal decision")
+	ErrAppealReviewer        = errors.New("appeals must be decided by a second reviewer")
+)
+
+// Actions written to the sanction's history for each appeal step, and to
+// its report's history when it came from a report
+const (
+	actionAppealFiled  = "appeal_filed"
+	actionAppealPrefix = "appeal_"
+)
+
+// AppealService files sanction appeals and routes them to a second reviewer
+type AppealService struct {
+	db *gorm.DB
+	ws *websocket.Hub
+	// cache is the sanction service's, cleared when a decision changes a sanction
+	cache *restrictionCache
+}
+
+// NewAppealService creates a new appeal service
+func NewAppealService(db *gorm.DB, ws *websocket.Hub, sanctions *SanctionService) *AppealService {
+	return &AppealService{
+		db:    db,
+		ws:    ws,
+		cache: sanctions.cache,
+	}
+}
+
+// AppealDecision is a reviewer's outcome for an appeal. Duration is required
+// to reduce a sanction and is measured from when it was issued.
+type AppealDecision struct {
+	Outcome  models.AppealStatus
+	Note     string
+	Duration time.Duration
+}
+
+// FileAppeal contests one of the user's sanctions in force. Each sanction can
+// be appealed once; shadow restrictions are reported as not found because
+// the user is not meant to know about them.
+func (s *AppealService) FileAppeal(ctx context.Context, userID, sanctionID uuid.UUID, statement string) (*models.SanctionAppeal, error) {
+	if strings.TrimSpace(statement) == "" {
+		return nil, ErrNoteRequired
+	}
+
+	var appeal *models.SanctionAppeal
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		sanction, err := lockSanction(tx, sanctionID)
+		if err != nil {
+			return err
+		}
+		if sanction.UserID != userID || sanction.Type == models.SanctionShadowRestriction {
+			return ErrSanctionNotFound
+		}
+		now := time.Now()
+		if !sanction.Active(now) {
+			return ErrSanctionNotInForce
+		}
+
+		var existing int64
+		if err := tx.Model(&models.SanctionAppeal{}).Where("sanction_id = ?", sanction.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrAppealExists
+		}
+
+		appeal = &models.SanctionAppeal{
+			ID:         uuid.New(),
+			SanctionID: sanction.ID,
+			UserID:     userID,
+			Statement:  statement,
+			Status:     models.AppealPending,
+			CreatedAt:  now,
+		}
+		if err := tx.Create(appeal).Error; err != nil {
+			return err
+		}
+		return recordAppealStep(tx, sanction, userID, actionAppealFiled, statement, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "sanction appealed",
+		slog.String("appeal_id", appeal.ID.String()),
+		slog.String("sanction_id", sanctionID.String()))
+	return appeal, nil
+}
+
+// ListMyAppeals returns the user's appeals, newest first. Reviewer identities
+// are left out.
+func (s *AppealService) ListMyAppeals(ctx context.Context, userID uuid.UUID) ([]models.SanctionAppeal, error) {
+	appeals := []models.SanctionAppeal{}
+	err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&appeals).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range appeals {
+		appeals[i].ReviewerID = nil
+	}
+	return appeals, nil
+}
+
+// ListAppealQueue returns the pending appeals the reviewer may decide,
+// oldest first: appeals against sanctions they issued, and their own
+// appeals, are left for someone else.
+func (s *AppealService) ListAppealQueue(ctx context.Context, reviewerID uuid.UUID) ([]models.SanctionAppeal, error) {
+	appeals := []models.SanctionAppeal{}
+	err := s.db.WithContext(ctx).
+		Joins("JOIN user_sanctions ON user_sanctions.id = sanction_appeals.sanction_id").
+		Where("sanction_appeals.status = ? AND sanction_appeals.user_id <> ?", models.AppealPending, reviewerID).
+		Where("user_sanctions.issued_by IS NULL OR user_sanctions.issued_by <> ?", reviewerID).
+		Preload("Sanction").
+		Order("sanction_appeals.created_at ASC").
+		Find(&appeals).Error
+	return appeals, err
+}
+
+// DecideAppeal records the reviewer's outcome, applies it to the sanction
+// and notifies the user
+func (s *AppealService) DecideAppeal(ctx context.Context, appealID, reviewerID uuid.UUID, decision AppealDecision) (*models.SanctionAppeal, error) {
+	if !decision.Outcome.Decision() {
+		return nil, fmt.Errorf("%w: unknown outcome %q", ErrInvalidAppealDecision, decision.Outcome)
+	}
+	if strings.TrimSpace(decision.Note) == "" {
+		return nil, ErrNoteRequired
+	}
+	if decision.Outcome == models.AppealReduced && decision.Duration <= 0 {
+		return nil, fmt.Errorf("%w: reducing a sanction needs a duration", ErrInvalidAppealDecision)
+	}
+
+	var appeal models.SanctionAppeal
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", appealID).First(&appeal).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAppealNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if appeal.Status != models.AppealPending {
+			return ErrAppealDecided
+		}
+
+		sanction, err := lockSanction(tx, appeal.SanctionID)
+		if err != nil {
+			return err
+		}
+		if appeal.UserID == reviewerID || (sanction.IssuedBy != nil && *sanction.IssuedBy == reviewerID) {
+			return ErrAppealReviewer
+		}
+
+		now := time.Now()
+		if err := applyAppealDecision(tx, sanction, reviewerID, decision, now); err != nil {
+			return err
+		}
+
+		appeal.Status = decision.Outcome
+		appeal.ReviewerID = &reviewerID
+		appeal.DecisionNote = decision.Note
+		appeal.DecidedAt = &now
+		if err := tx.Model(&appeal).Updates(map[string]interface{}{
+			"status":        appeal.Status,
+			"reviewer_id":   reviewerID,
+			"decision_note": appeal.DecisionNote,
+			"decided_at":    now,
+		}).Error; err != nil {
+			return err
+		}
+		appeal.Sanction = sanction
+
+		return recordAppealStep(tx, sanction, reviewerID, actionAppealPrefix+string(decision.Outcome), decision.Note, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.cache.forget(appeal.UserID)
+
+	slog.InfoContext(ctx, "appeal decided",
+		slog.String("appeal_id", appeal.ID.String()),
+		slog.String("sanction_id", appeal.SanctionID.String()),
+		slog.String("outcome", string(appeal.Status)))
+
+	notice := appeal
+	notice.ReviewerID = nil
+	s.ws.BroadcastToUser(ctx, appeal.UserID, "appeal_decided", notice)
+	return &appeal, nil
+}
+
+// recordAppealStep adds an appeal step to the sanction's history and to the
+// history of the report it came from, if any
+func recordAppealStep(tx *gorm.DB, sanction *models.UserSanction, actorID uuid.UUID, action, note string, at time.Time) error {
+	if err := recordSanctionEvent(tx, sanction.ID, actorID, action, note, at); err != nil {
+		return err
+	}
+	if sanction.ReportID != nil {
+		return recordReportAction(tx, *sanction.ReportID, actorID, action, note, at)
+	}
+	return nil
+}
+
+// applyAppealDecision updates the sanction for an appeal outcome. Any
+// sanction still pending review counts as reviewed once its appeal is decided.
+func applyAppealDecision(tx *gorm.DB, sanction *models.UserSanction, reviewerID uuid.UUID, decision AppealDecision, at time.Time) error {
+	updates := map[string]interface{}{"pending_review": false}
+	sanction.PendingReview = false
+
+	switch decision.Outcome {
+	case models.AppealReduced:
+		if sanction.Type == models.SanctionWarning {
+			return fmt.Errorf("%w: a warning cannot be reduced", ErrInvalidAppealDecision)
+		}
+		expires := sanction.CreatedAt.Add(decision.Duration)
+		if sanction.ExpiresAt != nil && !expires.Before(*sanction.ExpiresAt) {
+			return fmt.Errorf("%w: the new duration must end before the sanction does", ErrInvalidAppealDecision)
+		}
+		updates["expires_at"] = expires
+		sanction.ExpiresAt = &expires
+		if sanction.Type == models.SanctionBan {
+			updates["type"] = models.SanctionSuspension
+			sanction.Type = models.SanctionSuspension
+		}
+	case models.AppealOverturned:
+		reason := "appeal overturned: " + decision.Note
+		updates["lifted_at"] = at
+		updates["lifted_by"] = reviewerID
+		updates["lift_reason"] = reason
+		sanction.LiftedAt = &at
+		sanction.LiftedBy = &reviewerID
+		sanction.LiftReason = reason
+	}
+	return tx.Model(sanction).Updates(updates).Error
+}
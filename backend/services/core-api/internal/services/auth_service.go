@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// AccountLockedError refuses a sign-in by a suspended or banned user. It
+// carries an appeal-scoped token so they can still review and appeal the
+// sanction without a full session.
+type AccountLockedError struct {
+	Err         error
+	AppealToken *auth.TokenPair
+}
+
+func (e *AccountLockedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AccountLockedError) Unwrap() error {
+	return e.Err
+}
+
+// AuthService handles login and token lifecycle operations
+type AuthService struct {
+	db     *gorm.DB
+	tokens *auth.TokenService
+}
+
+// NewAuthService creates a new auth service
+func NewAuthService(db *gorm.DB, tokens *auth.TokenService) *AuthService {
+	return &AuthService{
+		db:     db,
+		tokens: tokens,
+	}
+}
+
+// Login verifies the user's credentials and issues a new token pair.
+// Suspended and banned users are refused with an AccountLockedError.
+func (s *AuthService) Login(ctx context.Context, email, password string) (*auth.TokenPair, *models.User, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidCredentials
+		}
+		return nil, nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.HashedPassword), []byte(password)); err != nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	if sanction, err := activeRestriction(ctx, s.db, user.ID); err != nil {
+		return nil, nil, err
+	} else if err := lockoutError(sanction); err != nil {
+		return nil, nil, s.locked(user.ID, err)
+	}
+
+	pair, err := s.tokens.IssuePair(ctx, user.ID, user.Role)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pair, &user, nil
+}
+
+// Refresh rotates a refresh token, picking up any role change, suspension or ban
+// since it was issued
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*auth.TokenPair, error) {
+	claims, err := s.tokens.ConsumeRefresh(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, auth.ErrInvalidToken
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Select("id", "role").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if sanction, err := activeRestriction(ctx, s.db, user.ID); err != nil {
+		return nil, err
+	} else if err := lockoutError(sanction); err != nil {
+		return nil, s.locked(user.ID, err)
+	}
+
+	return s.tokens.Rotate(ctx, claims, user.Role)
+}
+
+// locked wraps a lockout error with an appeal-scoped token for the user
+func (s *AuthService) locked(userID uuid.UUID, lockout error) error {
+	token, err := s.tokens.IssueScoped(userID, auth.ScopeAppeal)
+	if err != nil {
+		return err
+	}
+	return &AccountLockedError{Err: lockout, AppealToken: token}
+}
+
+// Logout revokes the presented access token and its refresh token family
+func (s *AuthService) Logout(ctx context.Context, claims *auth.Claims) error {
+	return s.tokens.Revoke(ctx, claims)
+}
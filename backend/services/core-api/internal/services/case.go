@@ -0,0 +1,758 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCaseNotFound      = errors.New("case not found")
+	ErrCaseLinkNotFound  = errors.New("case link not found")
+	ErrInvalidCaseStatus = errors.New("invalid case status")
+	ErrInvalidCaseLink   = errors.New("set exactly one of report_id, alert_id or sanction_id")
+	ErrAlreadyInCase     = errors.New("record already belongs to a case")
+	ErrInvalidCaseOwner  = errors.New("case owner must be a moderator")
+	ErrTitleRequired     = errors.New("a title is required")
+)
+
+// Case actions recorded in case_events
+const (
+	caseActionCreated  = "created"
+	caseActionRetitled = "retitled"
+	caseActionStatus   = "status_changed"
+	caseActionAssigned = "assigned"
+	caseActionLinked   = "linked"
+	caseActionUnlinked = "unlinked"
+)
+
+// Report history actions written when a report joins or leaves a case
+const (
+	reportActionAddedToCase     = "added_to_case"
+	reportActionRemovedFromCase = "removed_from_case"
+)
+
+// maxMergeCandidates caps the suggestions returned at once
+const maxMergeCandidates = 50
+
+// CaseService groups reports, alerts and sanctions into investigations
+type CaseService struct {
+	db *gorm.DB
+	// mergeWindow is how close in time two reports about the same user must
+	// be filed for one to be suggested for the other's case
+	mergeWindow time.Duration
+}
+
+// NewCaseService creates a new case service
+func NewCaseService(db *gorm.DB, mergeWindow time.Duration) *CaseService {
+	return &CaseService{
+		db:          db,
+		mergeWindow: mergeWindow,
+	}
+}
+
+// CaseLinkTarget names the one record a link points at
+type CaseLinkTarget struct {
+	ReportID   *uuid.UUID
+	AlertID    *uuid.UUID
+	SanctionID *uuid.UUID
+}
+
+func (t CaseLinkTarget) count() int {
+	n := 0
+	for _, id := range []*uuid.UUID{t.ReportID, t.AlertID, t.SanctionID} {
+		if id != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// CaseUpdate changes a case's title, status or owner; nil fields are kept
+type CaseUpdate struct {
+	Title   *string
+	Status  *models.CaseStatus
+	OwnerID *uuid.UUID
+	// Unassign clears the owner
+	Unassign bool
+}
+
+// CaseFilter narrows ListCases; zero fields match every case
+type CaseFilter struct {
+	Status    *models.CaseStatus
+	OwnerID   *uuid.UUID
+	SubjectID *uuid.UUID
+}
+
+// CaseDetail is a case with everything linked to it and its notes
+type CaseDetail struct {
+	models.Case
+	Links     []models.CaseLink       `json:"links"`
+	Reports   []models.SafetyReport   `json:"reports"`
+	Alerts    []models.EmergencyAlert `json:"alerts"`
+	Sanctions []models.UserSanction   `json:"sanctions"`
+	Notes     []models.NoteThread     `json:"notes"`
+}
+
+// TimelineEntry is one dated item in a case's timeline
+type TimelineEntry struct {
+	At      time.Time  `json:"at"`
+	Kind    string     `json:"kind"`
+	RefID   uuid.UUID  `json:"ref_id"`
+	ActorID *uuid.UUID `json:"actor_id,omitempty"`
+	Summary string     `json:"summary"`
+}
+
+// MergeCandidate is a report that may belong with another: it is about the
+// same user and was filed within the merge window of MatchedReportID
+type MergeCandidate struct {
+	Report          models.SafetyReport `json:"report"`
+	MatchedReportID uuid.UUID           `json:"matched_report_id"`
+	Gap             string              `json:"gap"`
+	// CaseID is set when the candidate already belongs to a case
+	CaseID *uuid.UUID `json:"case_id,omitempty"`
+}
+
+// CreateCase opens a case, optionally starting with some reports
+func (s *CaseService) CreateCase(ctx context.Context, actorID uuid.UUID, title string, subjectID *uuid.UUID, reportIDs []uuid.UUID) (*models.Case, error) {
+	if strings.TrimSpace(title) == "" {
+		return nil, ErrTitleRequired
+	}
+
+	now := time.Now()
+	c := &models.Case{
+		ID:        uuid.New(),
+		Title:     title,
+		Status:    models.CaseOpen,
+		SubjectID: subjectID,
+		CreatedBy: actorID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(c).Error; err != nil {
+			return err
+		}
+		if err := recordCaseEvent(tx, c.ID, actorID, caseActionCreated, title, now); err != nil {
+			return err
+		}
+		for i := range reportIDs {
+			if _, err := linkToCase(tx, c, actorID, CaseLinkTarget{ReportID: &reportIDs[i]}, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ListCases returns cases matching filter, most recently updated first
+func (s *CaseService) ListCases(ctx context.Context, filter CaseFilter) ([]models.Case, error) {
+	q := s.db.WithContext(ctx).Model(&models.Case{})
+	if filter.Status != nil {
+		q = q.Where("status = ?", *filter.Status)
+	}
+	if filter.OwnerID != nil {
+		q = q.Where("owner_id = ?", *filter.OwnerID)
+	}
+	if filter.SubjectID != nil {
+		q = q.Where("subject_id = ?", *filter.SubjectID)
+	}
+	cases := []models.Case{}
+	err := q.Order("updated_at DESC").Find(&cases).Error
+	return cases, err
+}
+
+// GetCase returns a case with its linked records and notes
+func (s *CaseService) GetCase(ctx context.Context, caseID uuid.UUID) (*CaseDetail, error) {
+	db := s.db.WithContext(ctx)
+
+	var c models.Case
+	err := db.Where("id = ?", caseID).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCaseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &CaseDetail{
+		Case:      c,
+		Links:     []models.CaseLink{},
+		Reports:   []models.SafetyReport{},
+		Alerts:    []models.EmergencyAlert{},
+		Sanctions: []models.UserSanction{},
+	}
+	if err := db.Where("case_id = ?", caseID).Order("created_at ASC").Find(&detail.Links).Error; err != nil {
+		return nil, err
+	}
+	reportIDs, alertIDs, sanctionIDs := splitLinks(detail.Links)
+	if len(reportIDs) > 0 {
+		if err := db.Where("id IN ?", reportIDs).Order("created_at ASC").Find(&detail.Reports).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(alertIDs) > 0 {
+		if err := db.Where("id IN ?", alertIDs).Order("created_at ASC").Find(&detail.Alerts).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(sanctionIDs) > 0 {
+		if err := db.Where("id IN ?", sanctionIDs).Order("created_at ASC").Find(&detail.Sanctions).Error; err != nil {
+			return nil, err
+		}
+	}
+	notes, err := listNotes(db, "case_id", caseID)
+	if err != nil {
+		return nil, err
+	}
+	detail.Notes = models.BuildNoteThreads(notes)
+	return detail, nil
+}
+
+// UpdateCase changes a case's title, status or owner. Owners must be able
+// to moderate reports.
+func (s *CaseService) UpdateCase(ctx context.Context, caseID, actorID uuid.UUID, update CaseUpdate) (*models.Case, error) {
+	if update.Status != nil && !update.Status.Valid() {
+		return nil, ErrInvalidCaseStatus
+	}
+	if update.Title != nil && strings.TrimSpace(*update.Title) == "" {
+		return nil, ErrTitleRequired
+	}
+
+	var c *models.Case
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if c, err = lockCase(tx, caseID); err != nil {
+			return err
+		}
+		now := time.Now()
+		updates := map[string]interface{}{"updated_at": now}
+
+		if update.Title != nil && *update.Title != c.Title {
+			updates["title"] = *update.Title
+			c.Title = *update.Title
+			if err := recordCaseEvent(tx, c.ID, actorID, caseActionRetitled, c.Title, now); err != nil {
+				return err
+			}
+		}
+
+		if update.Status != nil && *update.Status != c.Status {
+			updates["status"] = *update.Status
+			if *update.Status == models.CaseClosed {
+				updates["closed_at"] = now
+				c.ClosedAt = &now
+			} else {
+				updates["closed_at"] = nil
+				c.ClosedAt = nil
+			}
+			detail := fmt.Sprintf("%s to %s", c.Status, *update.Status)
+			c.Status = *update.Status
+			if err := recordCaseEvent(tx, c.ID, actorID, caseActionStatus, detail, now); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case update.Unassign && c.OwnerID != nil:
+			updates["owner_id"] = nil
+			c.OwnerID = nil
+			if err := recordCaseEvent(tx, c.ID, actorID, caseActionAssigned, "unassigned", now); err != nil {
+				return err
+			}
+		case update.OwnerID != nil && (c.OwnerID == nil || *c.OwnerID != *update.OwnerID):
+			if err := checkModerator(tx, *update.OwnerID, ErrInvalidCaseOwner); err != nil {
+				return err
+			}
+			updates["owner_id"] = *update.OwnerID
+			c.OwnerID = update.OwnerID
+			if err := recordCaseEvent(tx, c.ID, actorID, caseActionAssigned, update.OwnerID.String(), now); err != nil {
+				return err
+			}
+		}
+
+		c.UpdatedAt = now
+		return tx.Model(c).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LinkRecord adds a report, alert or sanction to a case
+func (s *CaseService) LinkRecord(ctx context.Context, caseID, actorID uuid.UUID, target CaseLinkTarget) (*models.CaseLink, error) {
+	var link *models.CaseLink
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		c, err := lockCase(tx, caseID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if link, err = linkToCase(tx, c, actorID, target, now); err != nil {
+			return err
+		}
+		return tx.Model(c).Update("updated_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// UnlinkRecord removes a link from a case
+func (s *CaseService) UnlinkRecord(ctx context.Context, caseID, linkID, actorID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		c, err := lockCase(tx, caseID)
+		if err != nil {
+			return err
+		}
+
+		var link models.CaseLink
+		err = tx.Where("id = ? AND case_id = ?", linkID, caseID).First(&link).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCaseLinkNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&link).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := recordCaseEvent(tx, c.ID, actorID, caseActionUnlinked, describeLink(&link), now); err != nil {
+			return err
+		}
+		if link.ReportID != nil {
+			if err := recordReportAction(tx, *link.ReportID, actorID, reportActionRemovedFromCase, c.ID.String(), now); err != nil {
+				return err
+			}
+		}
+		return tx.Model(c).Update("updated_at", now).Error
+	})
+}
+
+// AddNote adds an internal note to a case, replying to parentID if set
+func (s *CaseService) AddNote(ctx context.Context, caseID, authorID uuid.UUID, parentID *uuid.UUID, body string) (*models.InternalNote, error) {
+	note := &models.InternalNote{
+		CaseID:   &caseID,
+		ParentID: parentID,
+		AuthorID: authorID,
+		Body:     body,
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		c, err := lockCase(tx, caseID)
+		if err != nil {
+			return err
+		}
+		if err := createNote(tx, note); err != nil {
+			return err
+		}
+		return tx.Model(c).Update("updated_at", note.CreatedAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// Notes returns a case's internal notes as threads
+func (s *CaseService) Notes(ctx context.Context, caseID uuid.UUID) ([]models.NoteThread, error) {
+	db := s.db.WithContext(ctx)
+	if err := requireRecord(db, &models.Case{}, caseID, ErrCaseNotFound); err != nil {
+		return nil, err
+	}
+	notes, err := listNotes(db, "case_id", caseID)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildNoteThreads(notes), nil
+}
+
+// Timeline merges the case's own changes with the history and notes of
+// everything linked to it, oldest first
+func (s *CaseService) Timeline(ctx context.Context, caseID uuid.UUID) ([]TimelineEntry, error) {
+	detail, err := s.GetCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	db := s.db.WithContext(ctx)
+
+	var events []models.CaseEvent
+	if err := db.Where("case_id = ?", caseID).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	entries := make([]TimelineEntry, 0, len(events))
+	for i := range events {
+		e := &events[i]
+		summary := "case " + strings.ReplaceAll(e.Action, "_", " ")
+		if e.Detail != "" {
+			summary += ": " + e.Detail
+		}
+		entries = append(entries, TimelineEntry{At: e.CreatedAt, Kind: "case", RefID: e.ID, ActorID: &e.ActorID, Summary: summary})
+	}
+
+	reportIDs := make([]uuid.UUID, 0, len(detail.Reports))
+	for i := range detail.Reports {
+		reportIDs = append(reportIDs, detail.Reports[i].ID)
+	}
+
+	notesQuery := db.Where("case_id = ?", caseID)
+	if len(reportIDs) > 0 {
+		notesQuery = notesQuery.Or("report_id IN ?", reportIDs)
+	}
+	var notes []models.InternalNote
+	if err := notesQuery.Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	for i := range notes {
+		n := &notes[i]
+		entries = append(entries, TimelineEntry{At: n.CreatedAt, Kind: "note", RefID: n.ID, ActorID: &n.AuthorID, Summary: n.Body})
+	}
+
+	if len(reportIDs) > 0 {
+		var reportEvents []models.ReportEvent
+		if err := db.Where("report_id IN ?", reportIDs).Find(&reportEvents).Error; err != nil {
+			return nil, err
+		}
+		for i := range reportEvents {
+			e := &reportEvents[i]
+			entries = append(entries, TimelineEntry{At: e.CreatedAt, Kind: "report", RefID: e.ReportID, ActorID: &e.ActorID, Summary: describeReportEvent(e)})
+		}
+	}
+
+	for i := range detail.Alerts {
+		a := &detail.Alerts[i]
+		summary := "emergency alert raised"
+		if a.Location != nil {
+			summary += fmt.Sprintf(" at %.5f, %.5f", a.Location.Latitude, a.Location.Longitude)
+		}
+		entries = append(entries, TimelineEntry{At: a.CreatedAt, Kind: "alert", RefID: a.ID, ActorID: &a.UserID, Summary: summary})
+		if a.ResolvedAt != nil {
+			entries = append(entries, TimelineEntry{At: *a.ResolvedAt, Kind: "alert", RefID: a.ID, Summary: "emergency alert resolved"})
+		}
+	}
+
+	for i := range detail.Sanctions {
+		sn := &detail.Sanctions[i]
+		entries = append(entries, TimelineEntry{At: sn.CreatedAt, Kind: "sanction", RefID: sn.ID, ActorID: sn.IssuedBy,
+			Summary: fmt.Sprintf("%s issued: %s", sn.Type, sn.Reason)})
+		if sn.LiftedAt != nil {
+			entries = append(entries, TimelineEntry{At: *sn.LiftedAt, Kind: "sanction", RefID: sn.ID, ActorID: sn.LiftedBy,
+				Summary: fmt.Sprintf("%s lifted: %s", sn.Type, sn.LiftReason)})
+		}
+	}
+
+	// Appeals against sanctions from a linked report are already in its history
+	inCase := make(map[uuid.UUID]bool, len(reportIDs))
+	for _, id := range reportIDs {
+		inCase[id] = true
+	}
+	sanctionIDs := make([]uuid.UUID, 0, len(detail.Sanctions))
+	for i := range detail.Sanctions {
+		if sn := &detail.Sanctions[i]; sn.ReportID == nil || !inCase[*sn.ReportID] {
+			sanctionIDs = append(sanctionIDs, sn.ID)
+		}
+	}
+	if len(sanctionIDs) > 0 {
+		var sanctionEvents []models.SanctionEvent
+		if err := db.Where("sanction_id IN ?", sanctionIDs).Find(&sanctionEvents).Error; err != nil {
+			return nil, err
+		}
+		for i := range sanctionEvents {
+			e := &sanctionEvents[i]
+			summary := strings.ReplaceAll(e.Action, "_", " ")
+			if e.Note != "" {
+				summary += ": " + e.Note
+			}
+			entries = append(entries, TimelineEntry{At: e.CreatedAt, Kind: "sanction", RefID: e.SanctionID, ActorID: &e.ActorID, Summary: summary})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.Before(entries[j].At) })
+	return entries, nil
+}
+
+// SuggestForCase returns reports not in the case that are about the same
+// users as its reports and were filed around the same time
+func (s *CaseService) SuggestForCase(ctx context.Context, caseID uuid.UUID) ([]MergeCandidate, error) {
+	db := s.db.WithContext(ctx)
+	var count int64
+	if err := db.Model(&models.Case{}).Where("id = ?", caseID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrCaseNotFound
+	}
+
+	var anchors []models.SafetyReport
+	if err := db.Where("id IN (?)", db.Model(&models.CaseLink{}).Select("report_id").
+		Where("case_id = ? AND report_id IS NOT NULL", caseID)).
+		Find(&anchors).Error; err != nil {
+		return nil, err
+	}
+	return s.mergeCandidates(ctx, anchors, &caseID)
+}
+
+// SuggestForReport returns other reports about the same user filed around
+// the same time, with the case each already belongs to
+func (s *CaseService) SuggestForReport(ctx context.Context, reportID uuid.UUID) ([]MergeCandidate, error) {
+	var report models.SafetyReport
+	err := s.db.WithContext(ctx).Where("id = ?", reportID).First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.mergeCandidates(ctx, []models.SafetyReport{report}, nil)
+}
+
+// mergeCandidates finds reports about the anchors' reported users filed
+// within the merge window of one of them. Anchors themselves, and reports
+// already in excludeCase, are left out. Each candidate is matched to its
+// nearest anchor.
+func (s *CaseService) mergeCandidates(ctx context.Context, anchors []models.SafetyReport, excludeCase *uuid.UUID) ([]MergeCandidate, error) {
+	candidates := []MergeCandidate{}
+	if len(anchors) == 0 {
+		return candidates, nil
+	}
+
+	reported := make(map[uuid.UUID][]*models.SafetyReport)
+	anchorIDs := make([]uuid.UUID, 0, len(anchors))
+	from, to := anchors[0].CreatedAt, anchors[0].CreatedAt
+	for i := range anchors {
+		a := &anchors[i]
+		reported[a.ReportedID] = append(reported[a.ReportedID], a)
+		anchorIDs = append(anchorIDs, a.ID)
+		if a.CreatedAt.Before(from) {
+			from = a.CreatedAt
+		}
+		if a.CreatedAt.After(to) {
+			to = a.CreatedAt
+		}
+	}
+	reportedIDs := make([]uuid.UUID, 0, len(reported))
+	for id := range reported {
+		reportedIDs = append(reportedIDs, id)
+	}
+
+	db := s.db.WithContext(ctx)
+	q := db.Where("reported_id IN ? AND id NOT IN ? AND created_at BETWEEN ? AND ?",
+		reportedIDs, anchorIDs, from.Add(-s.mergeWindow), to.Add(s.mergeWindow))
+	if excludeCase != nil {
+		q = q.Where("id NOT IN (?)", db.Model(&models.CaseLink{}).Select("report_id").
+			Where("case_id = ? AND report_id IS NOT NULL", *excludeCase))
+	}
+	var reports []models.SafetyReport
+	if err := q.Order("created_at ASC").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range reports {
+		r := &reports[i]
+		var nearest *models.SafetyReport
+		var gap time.Duration
+		for _, a := range reported[r.ReportedID] {
+			d := r.CreatedAt.Sub(a.CreatedAt)
+			if d < 0 {
+				d = -d
+			}
+			if d <= s.mergeWindow && (nearest == nil || d < gap) {
+				nearest, gap = a, d
+			}
+		}
+		if nearest == nil {
+			continue
+		}
+		candidates = append(candidates, MergeCandidate{Report: *r, MatchedReportID: nearest.ID, Gap: gap.Round(time.Minute).String()})
+		if len(candidates) == maxMergeCandidates {
+			break
+		}
+	}
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(candidates))
+	for i := range candidates {
+		ids = append(ids, candidates[i].Report.ID)
+	}
+	var links []models.CaseLink
+	if err := db.Where("report_id IN ?", ids).Find(&links).Error; err != nil {
+		return nil, err
+	}
+	inCase := make(map[uuid.UUID]uuid.UUID, len(links))
+	for _, l := range links {
+		inCase[*l.ReportID] = l.CaseID
+	}
+	for i := range candidates {
+		if caseID, ok := inCase[candidates[i].Report.ID]; ok {
+			candidates[i].CaseID = &caseID
+		}
+	}
+	return candidates, nil
+}
+
+// lockCase loads a case with a row lock held until the transaction ends
+func lockCase(tx *gorm.DB, caseID uuid.UUID) (*models.Case, error) {
+	var c models.Case
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", caseID).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCaseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// linkToCase checks that the target exists and is in no case, then links it
+func linkToCase(tx *gorm.DB, c *models.Case, actorID uuid.UUID, target CaseLinkTarget, at time.Time) (*models.CaseLink, error) {
+	if target.count() != 1 {
+		return nil, ErrInvalidCaseLink
+	}
+
+	var existing int64
+	var err error
+	switch {
+	case target.ReportID != nil:
+		err = requireRecord(tx, &models.SafetyReport{}, *target.ReportID, ErrReportNotFound)
+		if err == nil {
+			err = tx.Model(&models.CaseLink{}).Where("report_id = ?", *target.ReportID).Count(&existing).Error
+		}
+	case target.AlertID != nil:
+		err = requireRecord(tx, &models.EmergencyAlert{}, *target.AlertID, ErrAlertNotFound)
+		if err == nil {
+			err = tx.Model(&models.CaseLink{}).Where("alert_id = ?", *target.AlertID).Count(&existing).Error
+		}
+	default:
+		err = requireRecord(tx, &models.UserSanction{}, *target.SanctionID, ErrSanctionNotFound)
+		if err == nil {
+			err = tx.Model(&models.CaseLink{}).Where("sanction_id = ?", *target.SanctionID).Count(&existing).Error
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, ErrAlreadyInCase
+	}
+
+	link := &models.CaseLink{
+		ID:         uuid.New(),
+		CaseID:     c.ID,
+		ReportID:   target.ReportID,
+		AlertID:    target.AlertID,
+		SanctionID: target.SanctionID,
+		AddedBy:    actorID,
+		CreatedAt:  at,
+	}
+	if err := tx.Create(link).Error; err != nil {
+		return nil, err
+	}
+	if err := recordCaseEvent(tx, c.ID, actorID, caseActionLinked, describeLink(link), at); err != nil {
+		return nil, err
+	}
+	if link.ReportID != nil {
+		if err := recordReportAction(tx, *link.ReportID, actorID, reportActionAddedToCase, c.ID.String(), at); err != nil {
+			return nil, err
+		}
+	}
+	return link, nil
+}
+
+// requireRecord returns notFound unless a row of model's table has the ID
+func requireRecord(tx *gorm.DB, model interface{}, id uuid.UUID, notFound error) error {
+	var count int64
+	if err := tx.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// checkModerator returns invalid unless the user's role may moderate reports
+func checkModerator(tx *gorm.DB, userID uuid.UUID, invalid error) error {
+	var user models.User
+	err := tx.Select("id", "role").Where("id = ?", userID).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return invalid
+	}
+	if err != nil {
+		return err
+	}
+	if !auth.Allowed(user.Role, auth.PermReportsModerate) {
+		return invalid
+	}
+	return nil
+}
+
+func recordCaseEvent(tx *gorm.DB, caseID, actorID uuid.UUID, action, detail string, at time.Time) error {
+	return tx.Create(&models.CaseEvent{
+		ID:        uuid.New(),
+		CaseID:    caseID,
+		ActorID:   actorID,
+		Action:    action,
+		Detail:    detail,
+		CreatedAt: at,
+	}).Error
+}
+
+func splitLinks(links []models.CaseLink) (reports, alerts, sanctions []uuid.UUID) {
+	for _, l := range links {
+		switch {
+		case l.ReportID != nil:
+			reports = append(reports, *l.ReportID)
+		case l.AlertID != nil:
+			alerts = append(alerts, *l.AlertID)
+		case l.SanctionID != nil:
+			sanctions = append(sanctions, *l.SanctionID)
+		}
+	}
+	return reports, alerts, sanctions
+}
+
+func describeLink(l *models.CaseLink) string {
+	switch {
+	case l.ReportID != nil:
+		return "report " + l.ReportID.String()
+	case l.AlertID != nil:
+		return "alert " + l.AlertID.String()
+	case l.SanctionID != nil:
+		return "sanction " + l.SanctionID.String()
+	}
+	return ""
+}
+
+func describeReportEvent(e *models.ReportEvent) string {
+	var summary string
+	switch {
+	case e.Action != "":
+		summary = strings.ReplaceAll(e.Action, "_", " ")
+	case e.FromStatus == nil:
+		summary = "report filed"
+	default:
+		summary = fmt.Sprintf("status %s to %s", *e.FromStatus, e.ToStatus)
+	}
+	if e.Note != "" {
+		summary += ": " + e.Note
+	}
+	return summary
+}
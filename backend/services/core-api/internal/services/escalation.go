@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"disco/core-api/internal/escalation"
+	"disco/core-api/internal/metrics"
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Escalation action names recorded in report_escalations
+const (
+	actionRaisePriority  = "raise_priority"
+	actionSuspend        = "suspend"
+	actionPageModerators = "page_moderators"
+)
+
+// errRuleNotMatched ends a rule's transaction without side effects
+var errRuleNotMatched = errors.New("escalation rule not matched")
+
+// escalate evaluates the escalation rules against the reports about the
+// reported user of a newly filed report. Failures are logged rather than
+// returned because the report itself has already been stored.
+func (s *SafetyService) escalate(ctx context.Context, report *models.SafetyReport) {
+	for i := range s.rules {
+		rule := &s.rules[i]
+		if !rule.AppliesTo(report.Type) {
+			continue
+		}
+
+		fired, suspension, err := s.applyRule(ctx, rule, report)
+		if err != nil {
+			slog.ErrorContext(ctx, "escalation rule failed",
+				slog.String("rule", rule.Name),
+				slog.String("report_id", report.ID.String()),
+				slog.Any("error", err))
+			continue
+		}
+		if fired == nil {
+			continue
+		}
+
+		metrics.ReportEscalations.WithLabelValues(rule.Name).Inc()
+		slog.WarnContext(ctx, "report escalated",
+			slog.String("rule", rule.Name),
+			slog.String("reported_id", fired.ReportedID.String()),
+			slog.Int("reports", fired.Reports),
+			slog.Int("distinct_reporters", fired.DistinctReporters),
+			slog.Any("actions", fired.Actions))
+		if suspension != nil {
+			s.cache.forget(suspension.UserID)
+			enforceSanction(ctx, s.ws, suspension)
+		}
+		if rule.Actions.PageModerators {
+			s.notifyModerators(ctx, "report_escalation", fired)
+		}
+	}
+}
+
+// applyRule counts the reports the rule matches and, if its thresholds are
+// met and it has not already fired for the user within its window, applies
+// its database actions. It returns the escalation recorded, or nil, and any
+// suspension it placed.
+func (s *SafetyService) applyRule(ctx context.Context, rule *escalation.Rule, report *models.SafetyReport) (*models.ReportEscalation, *models.UserSanction, error) {
+	now := time.Now()
+	since := now.Add(-rule.Match.Window)
+	var fired *models.ReportEscalation
+	var suspension *models.UserSanction
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serialise evaluation per reported user so concurrent reports fire a rule once
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "escalation:"+report.ReportedID.String()).Error; err != nil {
+			return err
+		}
+
+		var recent int64
+		if err := tx.Model(&models.ReportEscalation{}).
+			Where("rule = ? AND reported_id = ? AND created_at > ?", rule.Name, report.ReportedID, since).
+			Count(&recent).Error; err != nil {
+			return err
+		}
+		if recent > 0 {
+			return errRuleNotMatched
+		}
+
+		stats, err := s.ruleStats(ctx, tx, rule, report.ReportedID, since)
+		if err != nil {
+			return err
+		}
+		if !rule.Matches(stats) {
+			return errRuleNotMatched
+		}
+
+		fired = &models.ReportEscalation{
+			ID:                uuid.New(),
+			Rule:              rule.Name,
+			ReportedID:        report.ReportedID,
+			ReportID:          report.ID,
+			Reports:           stats.Reports,
+			DistinctReporters: stats.DistinctReporters,
+			Actions:           []string{},
+			CreatedAt:         now,
+		}
+
+		// Escalation never lifts a report to emergency priority
+		if p := min(rule.Actions.RaisePriority, maxPriority-1); p > 0 {
+			updates := map[string]interface{}{"priority": p}
+			// Raised reports move to the SLA of their new tier, unless they
+			// were already due sooner
+			if within, ok := models.SLAWindow(s.sla, p); ok {
+				updates["sla_deadline"] = gorm.Expr("LEAST(sla_deadline, created_at + make_interval(secs => ?))", within.Seconds())
+			}
+			if err := tx.Model(&models.SafetyReport{}).
+				Where("reported_id = ? AND status IN ? AND priority < ?", report.ReportedID, models.OpenStatuses, p).
+				Updates(updates).Error; err != nil {
+				return err
+			}
+			fired.Actions = append(fired.Actions, actionRaisePriority)
+		}
+
+		if rule.Actions.Suspend {
+			restriction, err := activeRestriction(ctx, tx, report.ReportedID)
+			if err != nil {
+				return err
+			}
+			// An existing lockout already covers the user
+			if restriction == nil || !restriction.Type.LocksOut() {
+				suspension = &models.UserSanction{
+					UserID:         report.ReportedID,
+					Type:           models.SanctionSuspension,
+					Reason:         "Your account is suspended while reports about it are reviewed.",
+					ReportID:       &report.ID,
+					EscalationRule: rule.Name,
+					CreatedAt:      now,
+				}
+				if err := issueSanction(tx, suspension, s.suspendFor); err != nil {
+					return err
+				}
+				fired.Actions = append(fired.Actions, actionSuspend)
+			}
+		}
+
+		if rule.Actions.PageModerators {
+			fired.Actions = append(fired.Actions, actionPageModerators)
+		}
+		return tx.Create(fired).Error
+	})
+	if errors.Is(err, errRuleNotMatched) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return fired, suspension, nil
+}
+
+// ruleStats counts the reports about reportedID since the start of the
+// rule's window, ignoring shadowed reports and reporters below the rule's
+// credibility threshold
+func (s *SafetyService) ruleStats(ctx context.Context, tx *gorm.DB, rule *escalation.Rule, reportedID uuid.UUID, since time.Time) (escalation.Stats, error) {
+	q := tx.Model(&models.SafetyReport{}).Where("reported_id = ? AND created_at > ? AND NOT shadowed", reportedID, since)
+	if len(rule.Match.Types) > 0 {
+		q = q.Where("type IN ?", rule.Match.Types)
+	}
+	var reporters []uuid.UUID
+	if err := q.Pluck("reporter_id", &reporters).Error; err != nil {
+		return escalation.Stats{}, err
+	}
+
+	if rule.Match.MinCredibility > 0 && len(reporters) > 0 {
+		signals, err := s.reporterCredibility(ctx, reporters)
+		if err != nil {
+			return escalation.Stats{}, err
+		}
+		credible := reporters[:0]
+		for _, id := range reporters {
+			if signals[id].Score >= rule.Match.MinCredibility {
+				credible = append(credible, id)
+			}
+		}
+		reporters = credible
+	}
+
+	distinct := make(map[uuid.UUID]bool, len(reporters))
+	for _, id := range reporters {
+		distinct[id] = true
+	}
+	return escalation.Stats{Reports: len(reporters), DistinctReporters: len(distinct)}, nil
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/storage"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// evidenceContentPath is the public route that serves signed downloads
+const evidenceContentPath = "/api/v1/evidence/%s/content"
+
+// sniffLen is how much of an upload http.DetectContentType looks at
+const sniffLen = 512
+
+var (
+	ErrForbidden         = errors.New("forbidden")
+	ErrEvidenceNotFound  = errors.New("evidence not found")
+	ErrEvidenceTooLarge  = errors.New("evidence file too large")
+	ErrUnsupportedType   = errors.New("unsupported evidence content type")
+	ErrInvalidSignature  = errors.New("invalid or expired download link")
+	ErrEvidenceNotUpload = errors.New("evidence is a link, not an upload")
+	ErrEmptyEvidenceFile = errors.New("evidence file is empty")
+	ErrInvalidEvidence   = errors.New("invalid evidence type")
+)
+
+// evidenceTypes are the types a client may give its evidence; anything else
+// is rejected rather than stored
+var evidenceTypes = map[string]bool{
+	"image":      true,
+	"video":      true,
+	"document":   true,
+	"screenshot": true,
+	"chat_log":   true,
+}
+
+// EvidenceLimits bounds what may be uploaded and how long download links live
+type EvidenceLimits struct {
+	MaxBytes     int64
+	AllowedTypes []string
+	URLTTL       time.Duration
+	// ResumableMaxBytes and ResumableTTL apply to tus uploads
+	ResumableMaxBytes int64
+	ResumableTTL      time.Duration
+}
+
+// EvidenceUpload is a file received for a report
+type EvidenceUpload struct {
+	File     io.Reader
+	Size     int64
+	FileName string
+	// Type overrides the evidence type derived from the content type
+	Type string
+}
+
+// EvidenceService stores evidence files and issues signed download URLs
+type EvidenceService struct {
+	db           *gorm.DB
+	store        storage.BlobStore
+	signer       *storage.Signer
+	maxBytes     int64
+	allowedTypes map[string]bool
+	urlTTL       time.Duration
+
+	resumableMaxBytes int64
+	resumableTTL      time.Duration
+}
+
+// NewEvidenceService creates a new evidence service
+func NewEvidenceService(db *gorm.DB, store storage.BlobStore, signer *storage.Signer, limits EvidenceLimits) *EvidenceService {
+	allowed := make(map[string]bool, len(limits.AllowedTypes))
+	for _, t := range limits.AllowedTypes {
+		allowed[strings.ToLower(t)] = true
+	}
+	return &EvidenceService{
+		db:           db,
+		store:        store,
+		signer:       signer,
+		maxBytes:     limits.MaxBytes,
+		allowedTypes: allowed,
+		urlTTL:       limits.URLTTL,
+
+		resumableMaxBytes: limits.ResumableMaxBytes,
+		resumableTTL:      limits.ResumableTTL,
+	}
+}
+
+// MaxBytes is the largest file Upload accepts
+func (s *EvidenceService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// Upload checks the file's sniffed content type against the allowlist, stores
+// it and records its SHA-256. Only the reporter and moderators may add
+// evidence to a report.
+func (s *EvidenceService) Upload(ctx context.Context, reportID, userID uuid.UUID, role models.Role, up EvidenceUpload) (*models.Evidence, error) {
+	report, err := s.findReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.ReporterID != userID && !auth.Allowed(role, auth.PermReportsModerate) {
+		return nil, ErrForbidden
+	}
+	if up.Size > s.maxBytes {
+		return nil, ErrEvidenceTooLarge
+	}
+	if up.Size == 0 {
+		return nil, ErrEmptyEvidenceFile
+	}
+	if err := checkEvidenceType(up.Type); err != nil {
+		return nil, err
+	}
+
+	contentType, head, err := s.sniff(up.File)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence := &models.Evidence{
+		ID:          uuid.New(),
+		ReportID:    &reportID,
+		Type:        up.Type,
+		UploaderID:  &userID,
+		FileName:    cleanFileName(up.FileName),
+		ContentType: contentType,
+		Size:        up.Size,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.save(ctx, s.db.WithContext(ctx), evidence, io.MultiReader(bytes.NewReader(head), up.File)); err != nil {
+		return nil, err
+	}
+
+	s.sign(evidence, userID)
+	return evidence, nil
+}
+
+// sniff detects the content type from the start of r rather than trusting the
+// client, and checks it against the allowlist. The bytes consumed are returned
+// so the caller can replay them.
+func (s *EvidenceService) sniff(r io.Reader) (string, []byte, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", nil, err
+	}
+	head = head[:n]
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !s.allowedTypes[contentType] {
+		return "", nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+	return contentType, head, nil
+}
+
+// save streams body into the blob store while hashing it, then inserts the
+// evidence row using db, linking report evidence into the report's hash
+// chain. The blob is removed again if the insert fails.
+func (s *EvidenceService) save(ctx context.Context, db *gorm.DB, evidence *models.Evidence, body io.Reader) error {
+	if evidence.Type == "" {
+		evidence.Type = evidenceType(evidence.ContentType)
+	}
+	if evidence.AlertID != nil {
+		evidence.StorageKey = "evidence/alerts/" + evidence.AlertID.String() + "/" + evidence.ID.String()
+	} else {
+		evidence.StorageKey = "evidence/" + evidence.ReportID.String() + "/" + evidence.ID.String()
+	}
+
+	hash := sha256.New()
+	if err := s.store.Put(ctx, evidence.StorageKey, io.TeeReader(body, hash), evidence.Size, evidence.ContentType); err != nil {
+		return fmt.Errorf("store evidence: %w", err)
+	}
+	evidence.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if evidence.ReportID != nil {
+			if err := appendToChain(tx, *evidence.ReportID, &evidence.ChainLink, &evidence.CreatedAt, evidence.ChainContent); err != nil {
+				return err
+			}
+		}
+		return tx.Create(evidence).Error
+	})
+	if err != nil {
+		s.discard(ctx, evidence.StorageKey)
+		return err
+	}
+
+	slog.InfoContext(ctx, "evidence stored",
+		slog.String("evidence_id", evidence.ID.String()),
+		slog.String("content_type", evidence.ContentType),
+		slog.Int64("size", evidence.Size))
+	return nil
+}
+
+// List returns a report's evidence with download URLs signed for userID.
+// Only the reporter and moderators may list it.
+func (s *EvidenceService) List(ctx context.Context, reportID, userID uuid.UUID, role models.Role) ([]models.Evidence, error) {
+	report, err := s.findReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.ReporterID != userID && !auth.Allowed(role, auth.PermReportsModerate) {
+		return nil, ErrForbidden
+	}
+
+	evidence := []models.Evidence{}
+	if err := s.db.WithContext(ctx).Where("report_id = ?", reportID).Order("created_at ASC").Find(&evidence).Error; err != nil {
+		return nil, err
+	}
+	for i := range evidence {
+		s.sign(&evidence[i], userID)
+	}
+	return evidence, nil
+}
+
+// Open checks a signed download link and returns the stored file. The caller
+// must close the reader.
+func (s *EvidenceService) Open(ctx context.Context, evidenceID, userID uuid.UUID, expires time.Time, sig string) (*models.Evidence, io.ReadCloser, error) {
+	if !s.signer.Verify(signedResource(evidenceID, userID), expires, sig) {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	var evidence models.Evidence
+	err := s.db.WithContext(ctx).Where("id = ?", evidenceID).First(&evidence).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrEvidenceNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if evidence.StorageKey == "" {
+		return nil, nil, ErrEvidenceNotUpload
+	}
+
+	rc, err := s.store.Open(ctx, evidence.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrEvidenceNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &evidence, rc, nil
+}
+
+func (s *EvidenceService) findReport(ctx context.Context, reportID uuid.UUID) (*models.SafetyReport, error) {
+	var report models.SafetyReport
+	err := s.db.WithContext(ctx).Select("id", "reporter_id").Where("id = ?", reportID).First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// sign fills in a download URL for uploaded evidence, bound to userID
+func (s *EvidenceService) sign(evidence *models.Evidence, userID uuid.UUID) {
+	if evidence.StorageKey == "" {
+		return
+	}
+	expires := time.Now().Add(s.urlTTL)
+	q := url.Values{}
+	q.Set("uid", userID.String())
+	q.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	q.Set("sig", s.signer.Sign(signedResource(evidence.ID, userID), expires))
+	evidence.DownloadURL = fmt.Sprintf(evidenceContentPath, evidence.ID) + "?" + q.Encode()
+}
+
+// discard removes a blob whose upload could not be completed
+func (s *EvidenceService) discard(ctx context.Context, key string) {
+	if err := s.store.Delete(context.WithoutCancel(ctx), key); err != nil {
+		slog.WarnContext(ctx, "failed to remove orphaned evidence blob",
+			slog.String("key", key), slog.Any("error", err))
+	}
+}
+
+func signedResource(evidenceID, userID uuid.UUID) string {
+	return "evidence/" + evidenceID.String() + "/" + userID.String()
+}
+
+// evidenceType maps a content type to the evidence type stored with it
+func evidenceType(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return "image"
+	case strings.HasPrefix(contentType, "video/"):
+		return "video"
+	}
+	return "document"
+}
+
+// checkEvidenceType rejects a client-supplied evidence type that is not
+// known. An empty type is derived from the content type when stored.
+func checkEvidenceType(t string) error {
+	if t != "" && !evidenceTypes[t] {
+		return fmt.Errorf("%w: %q", ErrInvalidEvidence, t)
+	}
+	return nil
+}
+
+// cleanFileName keeps only the base name of a client-supplied file name
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"disco/core-api/internal/export"
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/storage"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExportDisabled    = errors.New("exports are disabled: no signing key is configured")
+	ErrReferenceRequired = errors.New("a request reference is required")
+)
+
+// relatedAlertWindow is how far either side of a report's filing time the
+// reporter's emergency alerts are included in its export
+const relatedAlertWindow = 24 * time.Hour
+
+// reportActionExported is the report history action written for an export
+const reportActionExported = "law_enforcement_export"
+
+// ExportRequest names the record to export and who asked for it.
+// Reference is the requesting agency's case or request number.
+type ExportRequest struct {
+	Kind      string
+	ID        uuid.UUID
+	ActorID   uuid.UUID
+	Reference string
+}
+
+// ExportResult is a finished bundle's manifest and detached signature
+type ExportResult struct {
+	Manifest  *export.Manifest
+	Signature string
+}
+
+// ExportService assembles signed law-enforcement bundles for a report or
+// emergency alert from the database and the evidence store
+type ExportService struct {
+	db     *gorm.DB
+	store  storage.BlobStore
+	safety *SafetyService
+	key    ed25519.PrivateKey
+}
+
+// NewExportService creates a new export service. A nil key disables exports.
+func NewExportService(db *gorm.DB, store storage.BlobStore, safety *SafetyService, key ed25519.PrivateKey) *ExportService {
+	return &ExportService{
+		db:     db,
+		store:  store,
+		safety: safety,
+		key:    key,
+	}
+}
+
+// PublicKey returns the key recipients verify bundles with, or nil
+func (s *ExportService) PublicKey() ed25519.PublicKey {
+	if s.key == nil {
+		return nil
+	}
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// Export writes the bundle for req to w and signs it. Exports of a report
+// are recorded in its history.
+func (s *ExportService) Export(ctx context.Context, w io.Writer, req ExportRequest) (*ExportResult, error) {
+	if s.key == nil {
+		return nil, ErrExportDisabled
+	}
+	if strings.TrimSpace(req.Reference) == "" {
+		return nil, ErrReferenceRequired
+	}
+
+	manifest := &export.Manifest{
+		Subject:     export.Subject{Kind: req.Kind, ID: req.ID},
+		Reference:   req.Reference,
+		GeneratedAt: time.Now().UTC(),
+		GeneratedBy: req.ActorID,
+		KeyID:       export.KeyID(s.PublicKey()),
+	}
+	digest := export.NewDigest()
+	bundle := export.NewWriter(io.MultiWriter(w, digest), manifest)
+
+	var err error
+	switch req.Kind {
+	case export.SubjectReport:
+		err = s.writeReport(ctx, bundle, req.ID)
+	case export.SubjectAlert:
+		err = s.writeAlert(ctx, bundle, req.ID)
+	default:
+		err = fmt.Errorf("unknown export subject %q", req.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := bundle.Close(); err != nil {
+		return nil, err
+	}
+
+	signature, err := export.Sign(s.key, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Kind == export.SubjectReport {
+		if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return recordReportAction(tx, req.ID, req.ActorID, reportActionExported, req.Reference, time.Now())
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	slog.WarnContext(ctx, "law enforcement export produced",
+		slog.String("subject", req.Kind),
+		slog.String("id", req.ID.String()),
+		slog.String("reference", req.Reference),
+		slog.String("actor_id", req.ActorID.String()),
+		slog.Int("files", len(manifest.Files)))
+	return &ExportResult{Manifest: manifest, Signature: signature}, nil
+}
+
+// writeReport adds a report, its status history and chain verification,
+// its evidence and the reporter's alerts around the time it was filed
+func (s *ExportService) writeReport(ctx context.Context, bundle *export.Writer, reportID uuid.UUID) error {
+	db := s.db.WithContext(ctx)
+
+	var report models.SafetyReport
+	err := db.Where("id = ?", reportID).First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrReportNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	history, err := s.safety.GetReportHistory(ctx, reportID)
+	if err != nil {
+		return err
+	}
+	chain, err := s.safety.VerifyReportChain(ctx, reportID)
+	if err != nil {
+		return err
+	}
+	if !chain.Valid {
+		bundle.Warn("the report's hash chain does not verify; see chain.json")
+	}
+
+	var alerts []models.EmergencyAlert
+	if err := db.Where("user_id = ? AND created_at BETWEEN ? AND ?", report.ReporterID,
+		report.CreatedAt.Add(-relatedAlertWindow), report.CreatedAt.Add(relatedAlertWindow)).
+		Order("created_at ASC").Find(&alerts).Error; err != nil {
+		return err
+	}
+	alertIDs := make([]uuid.UUID, 0, len(alerts))
+	for i := range alerts {
+		alertIDs = append(alertIDs, alerts[i].ID)
+	}
+
+	q := db.Where("report_id = ?", reportID)
+	if len(alertIDs) > 0 {
+		q = q.Or("alert_id IN ?", alertIDs)
+	}
+	var evidence []models.Evidence
+	if err := q.Order("created_at ASC").Find(&evidence).Error; err != nil {
+		return err
+	}
+
+	records := []struct {
+		name string
+		v    interface{}
+	}{
+		{"report.json", report},
+		{"history.json", history},
+		{"chain.json", chain},
+		{"alerts.json", alerts},
+	}
+	for _, r := range records {
+		if err := bundle.AddJSON(r.name, r.v); err != nil {
+			return err
+		}
+	}
+	return s.writeEvidence(ctx, bundle, evidence)
+}
+
+// writeAlert adds an emergency alert with its location and evidence
+func (s *ExportService) writeAlert(ctx context.Context, bundle *export.Writer, alertID uuid.UUID) error {
+	db := s.db.WithContext(ctx)
+
+	var alert models.EmergencyAlert
+	err := db.Where("id = ?", alertID).First(&alert).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAlertNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var evidence []models.Evidence
+	if err := db.Where("alert_id = ?", alertID).Order("created_at ASC").Find(&evidence).Error; err != nil {
+		return err
+	}
+
+	if err := bundle.AddJSON("alert.json", alert); err != nil {
+		return err
+	}
+	return s.writeEvidence(ctx, bundle, evidence)
+}
+
+// writeEvidence adds the evidence records and every stored file. A file
+// that is missing or no longer matches its recorded hash is noted in the
+// manifest's warnings rather than failing the export.
+func (s *ExportService) writeEvidence(ctx context.Context, bundle *export.Writer, evidence []models.Evidence) error {
+	if err := bundle.AddJSON("evidence.json", evidence); err != nil {
+		return err
+	}
+
+	for i := range evidence {
+		e := &evidence[i]
+		if e.StorageKey == "" {
+			continue
+		}
+
+		rc, err := s.store.Open(ctx, e.StorageKey)
+		if errors.Is(err, storage.ErrNotFound) {
+			bundle.Warn("evidence %s: stored file is missing", e.ID)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("open evidence %s: %w", e.ID, err)
+		}
+		sum, err := bundle.AddFile("evidence/"+e.ID.String()+"/"+safeFileName(e.FileName), &e.ID, rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("copy evidence %s: %w", e.ID, err)
+		}
+		if e.SHA256 != "" && sum != e.SHA256 {
+			bundle.Warn("evidence %s: file hash %s does not match the recorded %s", e.ID, sum, e.SHA256)
+		}
+	}
+	return nil
+}
+
+// safeFileName reduces an uploaded file name to characters that are safe in
+// any archive tool
+func safeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+	name = strings.TrimLeft(name, ".")
+	if name == "" {
+		return "file"
+	}
+	return name
+}
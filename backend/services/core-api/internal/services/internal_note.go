@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrParentNoteNotFound = errors.New("parent note not found")
+
+// AddReportNote adds an internal note to a report, replying to parentID if set
+func (s *SafetyService) AddReportNote(ctx context.Context, reportID, authorID uuid.UUID, parentID *uuid.UUID, body string) (*models.InternalNote, error) {
+	note := &models.InternalNote{
+		ReportID: &reportID,
+		ParentID: parentID,
+		AuthorID: authorID,
+		Body:     body,
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := requireRecord(tx, &models.SafetyReport{}, reportID, ErrReportNotFound); err != nil {
+			return err
+		}
+		return createNote(tx, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// ReportNotes returns a report's internal notes as threads
+func (s *SafetyService) ReportNotes(ctx context.Context, reportID uuid.UUID) ([]models.NoteThread, error) {
+	db := s.db.WithContext(ctx)
+	if err := requireRecord(db, &models.SafetyReport{}, reportID, ErrReportNotFound); err != nil {
+		return nil, err
+	}
+	notes, err := listNotes(db, "report_id", reportID)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildNoteThreads(notes), nil
+}
+
+// createNote stores a note. A reply's parent must be on the same report or case.
+func createNote(tx *gorm.DB, note *models.InternalNote) error {
+	if strings.TrimSpace(note.Body) == "" {
+		return ErrNoteRequired
+	}
+
+	if note.ParentID != nil {
+		q := tx.Model(&models.InternalNote{}).Where("id = ?", *note.ParentID)
+		if note.ReportID != nil {
+			q = q.Where("report_id = ?", *note.ReportID)
+		} else {
+			q = q.Where("case_id = ?", *note.CaseID)
+		}
+		var count int64
+		if err := q.Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrParentNoteNotFound
+		}
+	}
+
+	note.ID = uuid.New()
+	note.CreatedAt = time.Now()
+	return tx.Create(note).Error
+}
+
+// listNotes returns the notes whose column matches id, oldest first
+func listNotes(db *gorm.DB, column string, id uuid.UUID) ([]models.InternalNote, error) {
+	notes := []models.InternalNote{}
+	err := db.Where(column+" = ?", id).Order("created_at ASC").Order("id ASC").Find(&notes).Error
+	return notes, err
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"disco/core-api/internal/metrics"
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDuplicateReport   = errors.New("a matching report was already filed")
+	ErrReportRateLimited = errors.New("too many reports")
+)
+
+// ReportLimits bounds how many reports one user may file. Zero disables a
+// limit. Emergency reports are exempt from every limit.
+type ReportLimits struct {
+	// DuplicateWindow is how long a report blocks another from the same
+	// reporter about the same user and incident type
+	DuplicateWindow time.Duration
+	// PerTargetLimit caps reports against one user within DuplicateWindow
+	PerTargetLimit int
+	// RateLimit caps all reports from one reporter within RateWindow
+	RateLimit  int
+	RateWindow time.Duration
+}
+
+// DuplicateReportError identifies the report a submission duplicates
+type DuplicateReportError struct {
+	ExistingID uuid.UUID
+}
+
+func (e *DuplicateReportError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrDuplicateReport, e.ExistingID)
+}
+
+func (e *DuplicateReportError) Unwrap() error {
+	return ErrDuplicateReport
+}
+
+// ReportLimitError reports which limit a submission hit and when it lifts
+type ReportLimitError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *ReportLimitError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrReportRateLimited, e.Reason)
+}
+
+func (e *ReportLimitError) Unwrap() error {
+	return ErrReportRateLimited
+}
+
+// checkReportLimits rejects duplicate and excessive reports other than
+// emergencies, which must always reach moderators. It takes a
+// transaction-scoped advisory lock on the reporter so concurrent submissions
+// are checked one at a time; it must run in the transaction that inserts the
+// report.
+func (s *SafetyService) checkReportLimits(tx *gorm.DB, report *models.SafetyReport) error {
+	if report.Type == models.IncidentTypeEmergency {
+		return nil
+	}
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "safety_report:"+report.ReporterID.String()).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+
+	if s.limits.DuplicateWindow > 0 {
+		since := now.Add(-s.limits.DuplicateWindow)
+
+		var existing models.SafetyReport
+		err := tx.Select("id").
+			Where("reporter_id = ? AND reported_id = ? AND type = ? AND created_at > ?",
+				report.ReporterID, report.ReportedID, report.Type, since).
+			Order("created_at DESC").First(&existing).Error
+		if err == nil {
+			metrics.ReportsRejected.WithLabelValues("duplicate").Inc()
+			return &DuplicateReportError{ExistingID: existing.ID}
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if s.limits.PerTargetLimit > 0 {
+			if err := s.checkWindow(tx, "reported user", s.limits.PerTargetLimit, s.limits.DuplicateWindow, now,
+				"reporter_id = ? AND reported_id = ? AND type <> ?", report.ReporterID, report.ReportedID, models.IncidentTypeEmergency); err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.limits.RateLimit > 0 && s.limits.RateWindow > 0 {
+		if err := s.checkWindow(tx, "reporter", s.limits.RateLimit, s.limits.RateWindow, now,
+			"reporter_id = ? AND type <> ?", report.ReporterID, models.IncidentTypeEmergency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWindow fails with a ReportLimitError when limit reports matching the
+// condition were filed within window. RetryAfter is when the oldest of them
+// leaves the window.
+func (s *SafetyService) checkWindow(tx *gorm.DB, scope string, limit int, window time.Duration, now time.Time, cond string, args ...interface{}) error {
+	var times []time.Time
+	err := tx.Model(&models.SafetyReport{}).
+		Where(cond, args...).
+		Where("created_at > ?", now.Add(-window)).
+		Order("created_at DESC").Limit(limit).
+		Pluck("created_at", &times).Error
+	if err != nil {
+		return err
+	}
+	if len(times) < limit {
+		return nil
+	}
+
+	metrics.ReportsRejected.WithLabelValues("rate_limit").Inc()
+	return &ReportLimitError{
+		Reason:     fmt.Sprintf("at most %d reports per %s per %s", limit, scope, window),
+		RetryAfter: times[len(times)-1].Add(window).Sub(now),
+	}
+}
+
+// ReporterCredibility returns the credibility signal for one reporter
+func (s *SafetyService) ReporterCredibility(ctx context.Context, reporterID uuid.UUID) (*models.ReporterCredibility, error) {
+	signals, err := s.reporterCredibility(ctx, []uuid.UUID{reporterID})
+	if err != nil {
+		return nil, err
+	}
+	return signals[reporterID], nil
+}
+
+// reporterCredibility scores each reporter by how their closed reports were
+// decided. Reporters without history get a neutral score.
+func (s *SafetyService) reporterCredibility(ctx context.Context, reporterIDs []uuid.UUID) (map[uuid.UUID]*models.ReporterCredibility, error) {
+	var rows []struct {
+		ReporterID uuid.UUID
+		Filed      int64
+		Resolved   int64
+		Dismissed  int64
+	}
+	err := s.db.WithContext(ctx).Model(&models.SafetyReport{}).
+		Select("reporter_id, COUNT(*) AS filed, "+
+			"COUNT(*) FILTER (WHERE status = ?) AS resolved, "+
+			"COUNT(*) FILTER (WHERE status = ?) AS dismissed",
+			models.IncidentStatusResolved, models.IncidentStatusDismissed).
+		Where("reporter_id IN ?", reporterIDs).
+		Group("reporter_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	signals := make(map[uuid.UUID]*models.ReporterCredibility, len(reporterIDs))
+	for _, id := range reporterIDs {
+		signals[id] = models.NewReporterCredibility(id, 0, 0, 0)
+	}
+	for _, r := range rows {
+		signals[r.ReporterID] = models.NewReporterCredibility(r.ReporterID, r.Filed, r.Resolved, r.Dismissed)
+	}
+	return signals, nil
+}
@@ -0,0 +1,507 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/metrics"
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReportClaimed      = errors.New("report is in review by another moderator")
+	ErrNotClaimHolder     = errors.New("you do not hold the claim on this report")
+	ErrInvalidAssignee    = errors.New("assignee must be a moderator")
+	ErrNoModerators       = errors.New("no moderator is available to assign")
+	ErrAssignmentDisabled = errors.New("automatic assignment is disabled; name an assignee")
+	ErrConflictOfInterest = errors.New("moderators cannot handle reports they filed or are the subject of")
+)
+
+// AssignmentStrategy chooses the moderator a report is assigned to automatically
+type AssignmentStrategy string
+
+const (
+	AssignmentOff AssignmentStrategy = "off"
+	// AssignmentRoundRobin picks the moderator whose last assignment is oldest
+	AssignmentRoundRobin AssignmentStrategy = "round_robin"
+	// AssignmentLeastLoaded picks the moderator with the fewest open
+	// assigned reports, then by round robin
+	AssignmentLeastLoaded AssignmentStrategy = "least_loaded"
+)
+
+// Report history actions written by assignment and claims
+const (
+	reportActionAssigned = "assigned"
+	reportActionClaimed  = "claimed"
+	reportActionReleased = "released"
+)
+
+// ReportAssignment configures how reports are assigned and claimed
+type ReportAssignment struct {
+	// Strategy assigns each new report automatically unless it is
+	// AssignmentOff or empty
+	Strategy AssignmentStrategy
+	// ClaimTTL is how long a claim lasts unless it is renewed
+	ClaimTTL time.Duration
+}
+
+// SetAssignment sets how new reports are assigned and claimed. Without it
+// reports are left unassigned. It must be called before the service is used.
+func (s *SafetyService) SetAssignment(assignment ReportAssignment) {
+	s.assignment = assignment
+}
+
+// ReportClaimedError identifies who holds the claim on a report
+type ReportClaimedError struct {
+	ClaimedBy uuid.UUID
+	ExpiresAt time.Time
+}
+
+func (e *ReportClaimedError) Error() string {
+	return ErrReportClaimed.Error()
+}
+
+func (e *ReportClaimedError) Unwrap() error {
+	return ErrReportClaimed
+}
+
+// ReportClaimNotice tells moderators a report was claimed or released.
+// InReviewBy is nil once released.
+type ReportClaimNotice struct {
+	ReportID   uuid.UUID              `json:"report_id"`
+	InReviewBy *models.ReportReviewer `json:"in_review_by"`
+}
+
+// ReportAssignmentNotice tells a moderator a report was assigned to them
+type ReportAssignmentNotice struct {
+	ReportID   uuid.UUID  `json:"report_id"`
+	AssigneeID uuid.UUID  `json:"assignee_id"`
+	AssignedBy *uuid.UUID `json:"assigned_by,omitempty"`
+	Priority   int        `json:"priority"`
+}
+
+// ModeratorStats is one moderator's current workload and their decisions
+// within the requested period
+type ModeratorStats struct {
+	ModeratorID uuid.UUID `json:"moderator_id"`
+	Username    string    `json:"username"`
+	// Assigned counts open reports assigned to the moderator
+	Assigned int64 `json:"assigned"`
+	// InReview counts reports the moderator holds a live claim on
+	InReview  int64 `json:"in_review"`
+	Resolved  int64 `json:"resolved"`
+	Dismissed int64 `json:"dismissed"`
+	// AvgDecisionSeconds is the mean time from filing to the moderator's
+	// decision over the reports they decided
+	AvgDecisionSeconds float64 `json:"avg_decision_seconds"`
+}
+
+// ClaimReport takes the review lock on a report for the actor, moving a
+// pending report into review. Claiming a report already held renews it.
+func (s *SafetyService) ClaimReport(ctx context.Context, reportID, actorID uuid.UUID) (*models.SafetyReport, error) {
+	var report *models.SafetyReport
+	var notification *models.ReportNotification
+	var renewed bool
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if report, err = lockReport(tx, reportID); err != nil {
+			return err
+		}
+		if report.Status.Closed() {
+			return fmt.Errorf("%w: closed reports cannot be claimed", ErrInvalidTransition)
+		}
+		now := time.Now()
+		if err := checkClaim(report, actorID, now); err != nil {
+			return err
+		}
+		renewed = report.ClaimHolder(now) != nil
+
+		if report.Status == models.IncidentStatusPending {
+			if notification, err = changeReportStatus(tx, report, actorID, models.IncidentStatusReviewing, ""); err != nil {
+				return err
+			}
+		}
+		if err := s.claim(tx, report, actorID, now); err != nil {
+			return err
+		}
+		if renewed {
+			return nil
+		}
+		return recordReportAction(tx, report.ID, actorID, reportActionClaimed, "", now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if notification != nil {
+		s.deliverOutcome(ctx, notification)
+	}
+	reviewers := []models.SafetyReport{*report}
+	if err := s.fillReviewers(ctx, reviewers); err != nil {
+		return nil, err
+	}
+	report = &reviewers[0]
+	if !renewed {
+		metrics.ReportAssignments.WithLabelValues("claim").Inc()
+		s.notifyModerators(ctx, "report_claimed", ReportClaimNotice{ReportID: report.ID, InReviewBy: report.InReviewBy})
+	}
+	return report, nil
+}
+
+// ReleaseReport gives up the actor's claim on a report, leaving it in the
+// queue for others
+func (s *SafetyService) ReleaseReport(ctx context.Context, reportID, actorID uuid.UUID) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		report, err := lockReport(tx, reportID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if holder := report.ClaimHolder(now); holder == nil || *holder != actorID {
+			return ErrNotClaimHolder
+		}
+		if err := releaseClaim(tx, report); err != nil {
+			return err
+		}
+		return recordReportAction(tx, report.ID, actorID, reportActionReleased, "", now)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notifyModerators(ctx, "report_released", ReportClaimNotice{ReportID: reportID})
+	return nil
+}
+
+// AssignReport assigns a report to assigneeID, or by the configured
+// strategy when assigneeID is nil. A live claim held by anyone other than
+// the new assignee is revoked.
+func (s *SafetyService) AssignReport(ctx context.Context, reportID, actorID uuid.UUID, assigneeID *uuid.UUID) (*models.SafetyReport, error) {
+	if assigneeID == nil && !s.autoAssigning() {
+		return nil, ErrAssignmentDisabled
+	}
+	return s.assign(ctx, reportID, &actorID, assigneeID)
+}
+
+// autoAssign assigns a newly filed report by the configured strategy
+func (s *SafetyService) autoAssign(ctx context.Context, report *models.SafetyReport) {
+	assigned, err := s.assign(ctx, report.ID, nil, nil)
+	if errors.Is(err, ErrNoModerators) {
+		slog.WarnContext(ctx, "no moderator available to assign report",
+			slog.String("report_id", report.ID.String()))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "automatic report assignment failed",
+			slog.String("report_id", report.ID.String()), slog.Any("error", err))
+		return
+	}
+	report.AssigneeID = assigned.AssigneeID
+	report.AssignedAt = assigned.AssignedAt
+}
+
+// assign sets a report's assignee. A nil actorID means the assignment is
+// automatic; a nil assigneeID picks one by the configured strategy.
+func (s *SafetyService) assign(ctx context.Context, reportID uuid.UUID, actorID, assigneeID *uuid.UUID) (*models.SafetyReport, error) {
+	var report *models.SafetyReport
+	var revoked *uuid.UUID
+	method := "manual"
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if report, err = lockReport(tx, reportID); err != nil {
+			return err
+		}
+		if report.Status.Closed() {
+			return fmt.Errorf("%w: closed reports cannot be assigned", ErrInvalidTransition)
+		}
+
+		var target uuid.UUID
+		if assigneeID != nil {
+			if err := checkModerator(tx, *assigneeID, ErrInvalidAssignee); err != nil {
+				return err
+			}
+			if err := checkConflict(report, *assigneeID); err != nil {
+				return err
+			}
+			target = *assigneeID
+		} else {
+			method = string(s.assignment.Strategy)
+			if target, err = s.pickModerator(tx, report); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"assignee_id": target,
+			"assigned_at": now,
+		}
+		if holder := report.ClaimHolder(now); holder != nil && *holder != target {
+			held := *holder
+			revoked = &held
+			updates["claimed_by"] = nil
+			updates["claim_expires_at"] = nil
+		}
+		if err := tx.Model(report).Updates(updates).Error; err != nil {
+			return err
+		}
+		report.AssigneeID = &target
+		report.AssignedAt = &now
+		if revoked != nil {
+			report.ClaimedBy = nil
+			report.ClaimExpiresAt = nil
+		}
+
+		actor, note := target, "assigned to "+target.String()
+		if actorID != nil {
+			actor = *actorID
+		} else {
+			note += " automatically (" + method + ")"
+		}
+		return recordReportAction(tx, report.ID, actor, reportActionAssigned, note, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.ReportAssignments.WithLabelValues(method).Inc()
+	notice := ReportAssignmentNotice{
+		ReportID:   report.ID,
+		AssigneeID: *report.AssigneeID,
+		AssignedBy: actorID,
+		Priority:   report.Priority,
+	}
+	s.ws.BroadcastToUser(ctx, notice.AssigneeID, "report_assigned", notice)
+	if revoked != nil {
+		s.notifyModerators(ctx, "report_released", ReportClaimNotice{ReportID: report.ID})
+	}
+	return report, nil
+}
+
+// pickModerator chooses an assignee by the configured strategy. Moderators
+// who filed or are the subject of the report, its current assignee and
+// moderators locked out of their accounts are passed over.
+func (s *SafetyService) pickModerator(tx *gorm.DB, report *models.SafetyReport) (uuid.UUID, error) {
+	// Serialise picks so concurrent reports see each other's assignments
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "report_assignment").Error; err != nil {
+		return uuid.Nil, err
+	}
+
+	exclude := []uuid.UUID{report.ReporterID, report.ReportedID}
+	if report.AssigneeID != nil {
+		exclude = append(exclude, *report.AssigneeID)
+	}
+	q := tx.Table("users AS u").
+		Joins(`LEFT JOIN (
+			SELECT assignee_id, COUNT(*) FILTER (WHERE status IN ?) AS open, MAX(assigned_at) AS last_assigned
+			FROM safety_reports WHERE assignee_id IS NOT NULL GROUP BY assignee_id
+		) AS a ON a.assignee_id = u.id`, models.OpenStatuses).
+		Where("u.role IN ? AND u.id NOT IN ?", auth.RolesWith(auth.PermReportsModerate), exclude).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM user_sanctions s
+			WHERE s.user_id = u.id AND s.type IN ? AND s.lifted_at IS NULL
+			AND (s.expires_at IS NULL OR s.expires_at > ?)
+		)`, []models.SanctionType{models.SanctionSuspension, models.SanctionBan}, time.Now())
+	if s.assignment.Strategy == AssignmentLeastLoaded {
+		q = q.Order("COALESCE(a.open, 0) ASC")
+	}
+	q = q.Order("a.last_assigned ASC NULLS FIRST").Order("u.id ASC")
+
+	var ids []uuid.UUID
+	if err := q.Limit(1).Pluck("u.id", &ids).Error; err != nil {
+		return uuid.Nil, err
+	}
+	if len(ids) == 0 {
+		return uuid.Nil, ErrNoModerators
+	}
+	return ids[0], nil
+}
+
+// ModeratorStats returns every moderator's workload and their decisions
+// made in [from, to)
+func (s *SafetyService) ModeratorStats(ctx context.Context, from, to time.Time) ([]ModeratorStats, error) {
+	db := s.db.WithContext(ctx)
+
+	var moderators []models.User
+	if err := db.Select("id", "username").
+		Where("role IN ?", auth.RolesWith(auth.PermReportsModerate)).
+		Order("username ASC").Find(&moderators).Error; err != nil {
+		return nil, err
+	}
+	stats := make([]ModeratorStats, len(moderators))
+	byID := make(map[uuid.UUID]*ModeratorStats, len(moderators))
+	for i := range moderators {
+		stats[i] = ModeratorStats{ModeratorID: moderators[i].ID, Username: moderators[i].Username}
+		byID[moderators[i].ID] = &stats[i]
+	}
+
+	var counts []struct {
+		ID uuid.UUID
+		N  int64
+	}
+	if err := db.Model(&models.SafetyReport{}).Select("assignee_id AS id, COUNT(*) AS n").
+		Where("assignee_id IS NOT NULL AND status IN ?", models.OpenStatuses).
+		Group("assignee_id").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		if st, ok := byID[c.ID]; ok {
+			st.Assigned = c.N
+		}
+	}
+
+	counts = nil
+	if err := db.Model(&models.SafetyReport{}).Select("claimed_by AS id, COUNT(*) AS n").
+		Where("claimed_by IS NOT NULL AND claim_expires_at > ?", time.Now()).
+		Group("claimed_by").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		if st, ok := byID[c.ID]; ok {
+			st.InReview = c.N
+		}
+	}
+
+	// Decisions are status changes to a closed status; actions recorded
+	// against already closed reports carry an action name and are skipped
+	var decisions []struct {
+		ID         uuid.UUID
+		Resolved   int64
+		Dismissed  int64
+		AvgSeconds float64
+	}
+	if err := db.Table("report_events AS e").
+		Select(`e.actor_id AS id,
+			COUNT(*) FILTER (WHERE e.to_status = ?) AS resolved,
+			COUNT(*) FILTER (WHERE e.to_status = ?) AS dismissed,
+			AVG(EXTRACT(EPOCH FROM e.created_at - r.created_at)) AS avg_seconds`,
+			models.IncidentStatusResolved, models.IncidentStatusDismissed).
+		Joins("JOIN safety_reports r ON r.id = e.report_id").
+		Where("e.to_status IN ? AND COALESCE(e.action, '') = ''",
+			[]models.IncidentStatus{models.IncidentStatusResolved, models.IncidentStatusDismissed}).
+		Where("e.created_at >= ? AND e.created_at < ?", from, to).
+		Group("e.actor_id").Scan(&decisions).Error; err != nil {
+		return nil, err
+	}
+	for _, d := range decisions {
+		if st, ok := byID[d.ID]; ok {
+			st.Resolved = d.Resolved
+			st.Dismissed = d.Dismissed
+			st.AvgDecisionSeconds = d.AvgSeconds
+		}
+	}
+	return stats, nil
+}
+
+// autoAssigning reports whether new reports are assigned automatically
+func (s *SafetyService) autoAssigning() bool {
+	return s.assignment.Strategy != "" && s.assignment.Strategy != AssignmentOff
+}
+
+// claim gives actorID the review lock on report for the claim TTL and
+// makes them its assignee
+func (s *SafetyService) claim(tx *gorm.DB, report *models.SafetyReport, actorID uuid.UUID, at time.Time) error {
+	expires := at.Add(s.assignment.ClaimTTL)
+	updates := map[string]interface{}{
+		"claimed_by":       actorID,
+		"claim_expires_at": expires,
+	}
+	if report.AssigneeID == nil || *report.AssigneeID != actorID {
+		updates["assignee_id"] = actorID
+		updates["assigned_at"] = at
+		report.AssigneeID = &actorID
+		report.AssignedAt = &at
+	}
+	if err := tx.Model(report).Updates(updates).Error; err != nil {
+		return err
+	}
+	report.ClaimedBy = &actorID
+	report.ClaimExpiresAt = &expires
+	return nil
+}
+
+// syncClaim keeps the claim in step with a status change: moving a report
+// into review claims it for the actor and closing it releases the claim
+func (s *SafetyService) syncClaim(tx *gorm.DB, report *models.SafetyReport, actorID uuid.UUID, status models.IncidentStatus, at time.Time) error {
+	switch {
+	case status == models.IncidentStatusReviewing:
+		return s.claim(tx, report, actorID, at)
+	case status.Closed():
+		return releaseClaim(tx, report)
+	}
+	return nil
+}
+
+func releaseClaim(tx *gorm.DB, report *models.SafetyReport) error {
+	if err := tx.Model(report).Updates(map[string]interface{}{
+		"claimed_by":       nil,
+		"claim_expires_at": nil,
+	}).Error; err != nil {
+		return err
+	}
+	report.ClaimedBy = nil
+	report.ClaimExpiresAt = nil
+	return nil
+}
+
+// checkClaim returns ErrConflictOfInterest if actorID filed or is the subject
+// of the report, and a ReportClaimedError if someone other than actorID
+// holds a live claim on it
+func checkClaim(report *models.SafetyReport, actorID uuid.UUID, now time.Time) error {
+	if err := checkConflict(report, actorID); err != nil {
+		return err
+	}
+	if holder := report.ClaimHolder(now); holder != nil && *holder != actorID {
+		return &ReportClaimedError{ClaimedBy: *holder, ExpiresAt: *report.ClaimExpiresAt}
+	}
+	return nil
+}
+
+// checkConflict refuses a report to the moderator who filed it or is its
+// subject, the same moderators pickModerator skips
+func checkConflict(report *models.SafetyReport, moderatorID uuid.UUID) error {
+	if moderatorID == report.ReporterID || moderatorID == report.ReportedID {
+		return ErrConflictOfInterest
+	}
+	return nil
+}
+
+// fillReviewers sets InReviewBy on reports with a live claim
+func (s *SafetyService) fillReviewers(ctx context.Context, reports []models.SafetyReport) error {
+	now := time.Now()
+	var holders []uuid.UUID
+	for i := range reports {
+		if holder := reports[i].ClaimHolder(now); holder != nil {
+			holders = append(holders, *holder)
+		}
+	}
+	if len(holders) == 0 {
+		return nil
+	}
+
+	var users []models.User
+	if err := s.db.WithContext(ctx).Select("id", "username").Where("id IN ?", holders).Find(&users).Error; err != nil {
+		return err
+	}
+	names := make(map[uuid.UUID]string, len(users))
+	for _, u := range users {
+		names[u.ID] = u.Username
+	}
+	for i := range reports {
+		if holder := reports[i].ClaimHolder(now); holder != nil {
+			reports[i].InReviewBy = &models.ReportReviewer{
+				UserID:    *holder,
+				Username:  names[*holder],
+				ExpiresAt: *reports[i].ClaimExpiresAt,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestCheckClaim(t *testing.T) {
+	now := time.Now()
+	reporter, reported, holder, other := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	expires := now.Add(time.Minute)
+	expired := now.Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		claim   *time.Time
+		actor   uuid.UUID
+		wantErr error
+	}{
+		{"unclaimed", nil, other, nil},
+		{"claim holder", &expires, holder, nil},
+		{"claimed by another", &expires, other, ErrReportClaimed},
+		{"expired claim", &expired, other, nil},
+		{"reporter", nil, reporter, ErrConflictOfInterest},
+		{"reported user", nil, reported, ErrConflictOfInterest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &models.SafetyReport{ReporterID: reporter, ReportedID: reported}
+			if tt.claim != nil {
+				report.ClaimedBy = &holder
+				report.ClaimExpiresAt = tt.claim
+			}
+			if err := checkClaim(report, tt.actor, now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkClaim() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Chain entry kinds reported by verification
+const (
+	chainKindEvent    = "report_event"
+	chainKindEvidence = "evidence"
+)
+
+// ChainBreak describes one place where a report's hash chain does not hold
+type ChainBreak struct {
+	Seq    int64      `json:"seq,omitempty"`
+	Kind   string     `json:"kind,omitempty"`
+	ID     *uuid.UUID `json:"id,omitempty"`
+	Reason string     `json:"reason"`
+}
+
+// ChainVerification is the result of re-walking a report's hash chain
+type ChainVerification struct {
+	ReportID uuid.UUID `json:"report_id"`
+	Valid    bool      `json:"valid"`
+	Entries  int       `json:"entries"`
+	// Unchained counts entries written before the chain was introduced
+	Unchained  int          `json:"unchained"`
+	Head       string       `json:"head,omitempty"`
+	Breaks     []ChainBreak `json:"breaks"`
+	VerifiedAt time.Time    `json:"verified_at"`
+}
+
+// chainEntry is a report event or evidence item loaded for verification
+type chainEntry struct {
+	kind      string
+	id        uuid.UUID
+	link      models.ChainLink
+	content   []string
+	createdAt time.Time
+}
+
+// appendToChain links a new entry to the end of its report's chain. It must
+// run in the transaction that inserts the entry, before the insert; the
+// report row stays locked until that transaction ends so entries are
+// appended one at a time. createdAt is truncated to the precision Postgres
+// stores so the hash still matches once the row is read back.
+func appendToChain(tx *gorm.DB, reportID uuid.UUID, link *models.ChainLink, createdAt *time.Time, content func() []string) error {
+	report, err := lockReport(tx, reportID)
+	if err != nil {
+		return err
+	}
+
+	*createdAt = createdAt.Truncate(time.Microsecond)
+	seq := report.ChainLength + 1
+	link.ChainSeq = &seq
+	link.PrevHash = report.ChainHead
+	if report.ChainLength == 0 {
+		link.PrevHash = chainHash("", report.ChainAnchor())
+	}
+	link.Hash = chainHash(link.PrevHash, content())
+
+	return tx.Model(&models.SafetyReport{}).Where("id = ?", reportID).UpdateColumns(map[string]interface{}{
+		"chain_length": seq,
+		"chain_head":   link.Hash,
+	}).Error
+}
+
+// chainHash is SHA-256 over the previous hash and the length-prefixed content
+// fields, so no two different entries share an encoding
+func chainHash(prev string, content []string) string {
+	h := sha256.New()
+	h.Write([]byte(prev))
+	for _, field := range content {
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.Itoa(len(field))))
+		h.Write([]byte{':'})
+		h.Write([]byte(field))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyReportChain re-walks a report's hash chain from its anchor to the
+// recorded head and lists every entry whose content, link or position does
+// not match
+func (s *SafetyService) VerifyReportChain(ctx context.Context, reportID uuid.UUID) (*ChainVerification, error) {
+	db := s.db.WithContext(ctx)
+
+	var report models.SafetyReport
+	err := db.Where("id = ?", reportID).First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var events []models.ReportEvent
+	if err := db.Where("report_id = ?", reportID).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	var evidence []models.Evidence
+	if err := db.Where("report_id = ?", reportID).Find(&evidence).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]chainEntry, 0, len(events)+len(evidence))
+	for i := range events {
+		e := &events[i]
+		entries = append(entries, chainEntry{chainKindEvent, e.ID, e.ChainLink, e.ChainContent(), e.CreatedAt})
+	}
+	for i := range evidence {
+		e := &evidence[i]
+		entries = append(entries, chainEntry{chainKindEvidence, e.ID, e.ChainLink, e.ChainContent(), e.CreatedAt})
+	}
+
+	result := verifyChain(&report, entries)
+	result.VerifiedAt = time.Now()
+	if !result.Valid {
+		slog.WarnContext(ctx, "report hash chain is broken",
+			slog.String("report_id", reportID.String()),
+			slog.Int("breaks", len(result.Breaks)))
+	}
+	return result, nil
+}
+
+// verifyChain checks entries against the report's anchor and head. Each
+// entry is hashed against its own stored PrevHash, so one altered entry is
+// reported once rather than breaking everything after it.
+func verifyChain(report *models.SafetyReport, entries []chainEntry) *ChainVerification {
+	result := &ChainVerification{
+		ReportID: report.ID,
+		Head:     report.ChainHead,
+		Breaks:   []ChainBreak{},
+	}
+
+	var chained, unchained []chainEntry
+	for _, e := range entries {
+		if e.link.ChainSeq == nil {
+			unchained = append(unchained, e)
+		} else {
+			chained = append(chained, e)
+		}
+	}
+	sort.Slice(chained, func(i, j int) bool {
+		return *chained[i].link.ChainSeq < *chained[j].link.ChainSeq
+	})
+	result.Entries = len(chained)
+	result.Unchained = len(unchained)
+
+	// Unchained rows are only legitimate if they predate the chain
+	for _, e := range unchained {
+		if len(chained) > 0 && e.createdAt.After(chained[0].createdAt) {
+			id := e.id
+			result.Breaks = append(result.Breaks, ChainBreak{
+				Kind:   e.kind,
+				ID:     &id,
+				Reason: "entry was added outside the chain",
+			})
+		}
+	}
+
+	prev := chainHash("", report.ChainAnchor())
+	var expected int64 = 1
+	for _, e := range chained {
+		id := e.id
+		seq := *e.link.ChainSeq
+		brk := func(reason string) {
+			result.Breaks = append(result.Breaks, ChainBreak{Seq: seq, Kind: e.kind, ID: &id, Reason: reason})
+		}
+
+		switch {
+		case seq < expected:
+			brk("duplicate sequence number")
+		case seq > expected:
+			brk(fmt.Sprintf("entries %d to %d are missing", expected, seq-1))
+		}
+
+		if e.link.PrevHash != prev {
+			if seq == 1 {
+				brk("report no longer matches the chain anchor")
+			} else {
+				brk("previous hash does not match the preceding entry")
+			}
+		}
+		if chainHash(e.link.PrevHash, e.content) != e.link.Hash {
+			brk("content does not match its hash")
+		}
+
+		prev = e.link.Hash
+		expected = seq + 1
+	}
+
+	// The recorded head catches entries removed from the end of the chain
+	switch {
+	case int64(len(chained)) != report.ChainLength:
+		result.Breaks = append(result.Breaks, ChainBreak{
+			Reason: fmt.Sprintf("chain has %d entries but the report records %d", len(chained), report.ChainLength),
+		})
+	case report.ChainLength > 0 && prev != report.ChainHead:
+		result.Breaks = append(result.Breaks, ChainBreak{
+			Reason: "last entry does not match the recorded chain head",
+		})
+	}
+
+	result.Valid = len(result.Breaks) == 0
+	return result
+}
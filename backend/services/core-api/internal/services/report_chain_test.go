@@ -0,0 +1,182 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var chainStart = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// buildChain links n report events to report the way appendToChain does
+func buildChain(report *models.SafetyReport, n int) []chainEntry {
+	entries := make([]chainEntry, 0, n)
+	prev := chainHash("", report.ChainAnchor())
+	for i := 1; i <= n; i++ {
+		seq := int64(i)
+		content := []string{"report_event", fmt.Sprintf("status_changed %d", i)}
+		hash := chainHash(prev, content)
+		entries = append(entries, chainEntry{
+			kind:      chainKindEvent,
+			id:        uuid.New(),
+			link:      models.ChainLink{ChainSeq: &seq, PrevHash: prev, Hash: hash},
+			content:   content,
+			createdAt: chainStart.Add(time.Duration(i) * time.Minute),
+		})
+		prev = hash
+	}
+	report.ChainLength = int64(n)
+	report.ChainHead = prev
+	return entries
+}
+
+func unchainedEntry(createdAt time.Time) chainEntry {
+	return chainEntry{
+		kind:      chainKindEvidence,
+		id:        uuid.New(),
+		content:   []string{"evidence", "photo.jpg"},
+		createdAt: createdAt,
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	tests := []struct {
+		name string
+		// tamper alters a valid five-entry chain
+		tamper      func(report *models.SafetyReport, entries []chainEntry) []chainEntry
+		wantReasons []string
+	}{
+		{
+			name:   "intact chain",
+			tamper: func(_ *models.SafetyReport, entries []chainEntry) []chainEntry { return entries },
+		},
+		{
+			name: "entries in storage order",
+			tamper: func(_ *models.SafetyReport, entries []chainEntry) []chainEntry {
+				return []chainEntry{entries[3], entries[0], entries[4], entries[2], entries[1]}
+			},
+		},
+		{
+			name: "unchained entry from before the chain",
+			tamper: func(_ *models.SafetyReport, entries []chainEntry) []chainEntry {
+				return append(entries, unchainedEntry(chainStart))
+			},
+		},
+		{
+			name: "duplicate seq",
+			tamper: func(_ *models.SafetyReport, entries []chainEntry) []chainEntry {
+				dup := entries[2]
+				dup.id = uuid.New()
+				return append(entries, dup)
+			},
+			wantReasons: []string{"duplicate sequence number", "previous hash does not match", "chain has 6 entries but the report records 5"},
+		},
+		{
+			name: "gap in seq",
+			tamper: func(_ *models.SafetyReport, entries []chainEntry) []chainEntry {
+				return append(entries[:2:2], entries[3:]...)
+			},
+			wantReasons: []string{"entries 3 to 3 are missing", "previous hash does not match", "chain has 4 entries but the report records 5"},
+		},
+		{
+			name: "tampered content",
+			tamper: func(_ *models.SafetyReport, entries []chainEntry) []chainEntry {
+				entries[1].content = []string{"report_event", "status_changed dismissed"}
+				return entries
+			},
+			wantReasons: []string{"content does not match its hash"},
+		},
+		{
+			name: "tampered content with rehashed entry",
+			tamper: func(_ *models.SafetyReport, entries []chainEntry) []chainEntry {
+				entries[1].content = []string{"report_event", "status_changed dismissed"}
+				entries[1].link.Hash = chainHash(entries[1].link.PrevHash, entries[1].content)
+				return entries
+			},
+			wantReasons: []string{"previous hash does not match"},
+		},
+		{
+			name: "wrong prev hash",
+			tamper: func(_ *models.SafetyReport, entries []chainEntry) []chainEntry {
+				entries[3].link.PrevHash = entries[1].link.Hash
+				return entries
+			},
+			wantReasons: []string{"previous hash does not match", "content does not match its hash"},
+		},
+		{
+			name: "report edited after filing",
+			tamper: func(report *models.SafetyReport, entries []chainEntry) []chainEntry {
+				report.Description = "edited"
+				return entries
+			},
+			wantReasons: []string{"report no longer matches the chain anchor"},
+		},
+		{
+			name: "truncated tail",
+			tamper: func(_ *models.SafetyReport, entries []chainEntry) []chainEntry {
+				return entries[:3]
+			},
+			wantReasons: []string{"chain has 3 entries but the report records 5"},
+		},
+		{
+			name: "truncated tail with rewound length",
+			tamper: func(report *models.SafetyReport, entries []chainEntry) []chainEntry {
+				report.ChainLength = 3
+				return entries[:3]
+			},
+			wantReasons: []string{"last entry does not match the recorded chain head"},
+		},
+		{
+			name: "unchained entry added after the chain began",
+			tamper: func(_ *models.SafetyReport, entries []chainEntry) []chainEntry {
+				return append(entries, unchainedEntry(chainStart.Add(time.Hour)))
+			},
+			wantReasons: []string{"entry was added outside the chain"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &models.SafetyReport{
+				ID:          uuid.New(),
+				ReporterID:  uuid.New(),
+				ReportedID:  uuid.New(),
+				Type:        models.IncidentTypeHarassment,
+				Description: "sent threatening messages",
+				CreatedAt:   chainStart,
+			}
+			entries := tt.tamper(report, buildChain(report, 5))
+
+			result := verifyChain(report, entries)
+			if result.Valid != (len(tt.wantReasons) == 0) {
+				t.Fatalf("Valid = %v, breaks = %+v", result.Valid, result.Breaks)
+			}
+			if len(result.Breaks) != len(tt.wantReasons) {
+				t.Fatalf("got %d breaks %+v, want %d", len(result.Breaks), result.Breaks, len(tt.wantReasons))
+			}
+			for i, want := range tt.wantReasons {
+				if !strings.Contains(result.Breaks[i].Reason, want) {
+					t.Errorf("break %d reason = %q, want it to contain %q", i, result.Breaks[i].Reason, want)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyChainEmpty(t *testing.T) {
+	report := &models.SafetyReport{ID: uuid.New(), CreatedAt: chainStart}
+
+	result := verifyChain(report, []chainEntry{unchainedEntry(chainStart.Add(time.Hour))})
+	if !result.Valid || result.Entries != 0 || result.Unchained != 1 {
+		t.Fatalf("verifyChain() = %+v, want a valid chain with one unchained entry", result)
+	}
+
+	report.ChainLength = 2
+	if result := verifyChain(report, nil); result.Valid {
+		t.Fatal("verifyChain() accepted a report whose entries were all removed")
+	}
+}
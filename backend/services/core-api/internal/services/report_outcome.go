@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MyReport is a report as its reporter sees it: what they filed and its
+// outcome, without moderator notes, priority or anything about sanctions
+type MyReport struct {
+	ID             uuid.UUID            `json:"id"`
+	ReportedID     uuid.UUID            `json:"reported_id"`
+	Type           models.IncidentType  `json:"type"`
+	Description    string               `json:"description"`
+	CreatedAt      time.Time            `json:"created_at"`
+	Outcome        models.ReportOutcome `json:"outcome"`
+	OutcomeMessage string               `json:"outcome_message"`
+	OutcomeAt      time.Time            `json:"outcome_at"`
+	// Responses are the messages moderators sent about the report, oldest first
+	Responses []ReporterResponse `json:"responses"`
+	// Unread is set while any notification about the report has not been read
+	Unread bool `json:"unread"`
+}
+
+// ReporterResponse is a moderator's message as its reporter sees it
+type ReporterResponse struct {
+	Message string     `json:"message"`
+	SentAt  time.Time  `json:"sent_at"`
+	ReadAt  *time.Time `json:"read_at"`
+}
+
+// MyReportPage is one page of a reporter's own reports, newest first
+type MyReportPage struct {
+	Reports    []MyReport `json:"reports"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// recordOutcome stores the reporter's notification for a status change.
+// Sanctions over the report must already be written in tx.
+func recordOutcome(tx *gorm.DB, report *models.SafetyReport, status models.IncidentStatus, at time.Time) (*models.ReportNotification, error) {
+	outcome, err := outcomeOf(tx, report.ID, status)
+	if err != nil {
+		return nil, err
+	}
+	notification := &models.ReportNotification{
+		ID:        uuid.New(),
+		UserID:    report.ReporterID,
+		ReportID:  report.ID,
+		Kind:      models.NotificationOutcome,
+		Outcome:   outcome,
+		Message:   outcome.Message(),
+		CreatedAt: at,
+	}
+	if err := tx.Create(notification).Error; err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+// outcomeOf returns the outcome shown for a report with the given status
+func outcomeOf(tx *gorm.DB, reportID uuid.UUID, status models.IncidentStatus) (models.ReportOutcome, error) {
+	if status != models.IncidentStatusResolved {
+		return models.OutcomeFor(status, false), nil
+	}
+	sanctioned, err := sanctionedReports(tx, []uuid.UUID{reportID})
+	if err != nil {
+		return "", err
+	}
+	return models.OutcomeFor(status, sanctioned[reportID]), nil
+}
+
+// sanctionedReports returns which of the reports a moderator sanctioned the
+// reported user over. Automatic sanctions only count once confirmed.
+func sanctionedReports(db *gorm.DB, reportIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	var ids []uuid.UUID
+	if err := db.Model(&models.UserSanction{}).
+		Where("report_id IN ? AND issued_by IS NOT NULL", reportIDs).
+		Distinct().Pluck("report_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	sanctioned := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		sanctioned[id] = true
+	}
+	return sanctioned, nil
+}
+
+// deliverOutcome pushes a stored notification to the reporter if they are
+// connected; otherwise it waits for them in ListMyReports
+func (s *SafetyService) deliverOutcome(ctx context.Context, notification *models.ReportNotification) {
+	s.ws.BroadcastToUser(ctx, notification.UserID, "report_outcome", notification)
+}
+
+// ListMyReports returns a page of the user's own reports with their outcomes
+func (s *SafetyService) ListMyReports(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*MyReportPage, error) {
+	page, err := s.ListReports(ctx, ReportFilter{
+		ReporterID: &userID,
+		Sort:       ReportSortNewest,
+		Cursor:     cursor,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MyReportPage{Reports: make([]MyReport, 0, len(page.Reports)), NextCursor: page.NextCursor}
+	if len(page.Reports) == 0 {
+		return result, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(page.Reports))
+	for i := range page.Reports {
+		ids = append(ids, page.Reports[i].ID)
+	}
+	sanctioned, err := sanctionedReports(s.db.WithContext(ctx), ids)
+	if err != nil {
+		return nil, err
+	}
+	var notifications []models.ReportNotification
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ? AND report_id IN ?", userID, ids).
+		Order("created_at DESC").
+		Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	latest := make(map[uuid.UUID]*models.ReportNotification, len(notifications))
+	unread := make(map[uuid.UUID]bool)
+	responses := make(map[uuid.UUID][]ReporterResponse)
+	for i := range notifications {
+		n := &notifications[i]
+		if n.ReadAt == nil {
+			unread[n.ReportID] = true
+		}
+		if n.Kind == models.NotificationResponse {
+			// Newest first here; reversed below
+			responses[n.ReportID] = append(responses[n.ReportID], ReporterResponse{Message: n.Message, SentAt: n.CreatedAt, ReadAt: n.ReadAt})
+			continue
+		}
+		if _, ok := latest[n.ReportID]; !ok {
+			latest[n.ReportID] = n
+		}
+	}
+
+	for i := range page.Reports {
+		r := &page.Reports[i]
+		outcome := models.OutcomeFor(r.Status, sanctioned[r.ID])
+		view := MyReport{
+			ID:             r.ID,
+			ReportedID:     r.ReportedID,
+			Type:           r.Type,
+			Description:    r.Description,
+			CreatedAt:      r.CreatedAt,
+			Outcome:        outcome,
+			OutcomeMessage: outcome.Message(),
+			OutcomeAt:      r.CreatedAt,
+			Responses:      []ReporterResponse{},
+			Unread:         unread[r.ID],
+		}
+		if n, ok := latest[r.ID]; ok {
+			view.OutcomeAt = n.CreatedAt
+		}
+		for j := len(responses[r.ID]) - 1; j >= 0; j-- {
+			view.Responses = append(view.Responses, responses[r.ID][j])
+		}
+		result.Reports = append(result.Reports, view)
+	}
+	return result, nil
+}
+
+// MarkOutcomesRead marks all of the user's report notifications, outcomes
+// and responses alike, as read
+func (s *SafetyService) MarkOutcomesRead(ctx context.Context, userID uuid.UUID) error {
+	return s.db.WithContext(ctx).Model(&models.ReportNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/metrics"
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Priority score weights. Only emergencies reach maxPriority, so no other
+// report outranks them however much history the reported user has.
+const (
+	maxPriority         = 100
+	evidencePoints      = 5
+	maxEvidencePoints   = 15
+	upheldReportPoints  = 10
+	maxUpheldPoints     = 30
+	openReportPoints    = 5
+	maxOpenReportPoints = 15
+)
+
+// scoreReport computes a new report's priority from its type, the evidence
+// filed with it and the reported user's history: reports against them that
+// were upheld, and other reports still awaiting review. Shadowed reports
+// do not count.
+func scoreReport(tx *gorm.DB, report *models.SafetyReport, evidence int) (int, error) {
+	if report.Type == models.IncidentTypeEmergency {
+		return maxPriority, nil
+	}
+
+	var history struct {
+		Upheld int
+		Open   int
+	}
+	err := tx.Model(&models.SafetyReport{}).
+		Select("COUNT(*) FILTER (WHERE status = ?) AS upheld, "+
+			"COUNT(DISTINCT reporter_id) FILTER (WHERE status IN ?) AS open",
+			models.IncidentStatusResolved, models.OpenStatuses).
+		Where("reported_id = ? AND id <> ? AND NOT shadowed", report.ReportedID, report.ID).
+		Scan(&history).Error
+	if err != nil {
+		return 0, err
+	}
+
+	score := models.DefaultPriority(report.Type) +
+		min(evidence*evidencePoints, maxEvidencePoints) +
+		min(history.Upheld*upheldReportPoints, maxUpheldPoints) +
+		min(history.Open*openReportPoints, maxOpenReportPoints)
+	return min(score, maxPriority-1), nil
+}
+
+// SLABreach is pushed to moderators when a report passes its SLA deadline
+type SLABreach struct {
+	ReportID    uuid.UUID           `json:"report_id"`
+	Type        models.IncidentType `json:"type"`
+	Priority    int                 `json:"priority"`
+	SLADeadline time.Time           `json:"sla_deadline"`
+	OverdueAt   time.Time           `json:"overdue_at"`
+}
+
+// MarkOverdueReports flags open reports past their SLA deadline and alerts
+// moderators. The update claims each report once, so several replicas can
+// run the checker without duplicate alerts.
+func (s *SafetyService) MarkOverdueReports(ctx context.Context) (int, error) {
+	now := time.Now()
+	var overdue []models.SafetyReport
+	err := s.db.WithContext(ctx).Model(&overdue).
+		Clauses(clause.Returning{Columns: []clause.Column{
+			{Name: "id"}, {Name: "type"}, {Name: "priority"}, {Name: "sla_deadline"},
+		}}).
+		Where("overdue_at IS NULL AND status IN ? AND sla_deadline < ?", models.OpenStatuses, now).
+		Update("overdue_at", now).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range overdue {
+		r := &overdue[i]
+		metrics.ReportSLABreaches.WithLabelValues(string(r.Type)).Inc()
+		slog.WarnContext(ctx, "report breached its SLA",
+			slog.String("report_id", r.ID.String()),
+			slog.Int("priority", r.Priority))
+		s.notifyModerators(ctx, "report_sla_breach", SLABreach{
+			ReportID:    r.ID,
+			Type:        r.Type,
+			Priority:    r.Priority,
+			SLADeadline: *r.SLADeadline,
+			OverdueAt:   now,
+		})
+	}
+	return len(overdue), nil
+}
+
+// RunSLAChecker marks overdue reports every interval until ctx is cancelled
+func (s *SafetyService) RunSLAChecker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.MarkOverdueReports(ctx); err != nil {
+				slog.ErrorContext(ctx, "checking report SLAs failed", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// moderatorChannel carries moderator notifications between replicas, since
+// each moderator's websocket is held by only one of them
+const moderatorChannel = "safety:moderator_notifications"
+
+// moderatorNotice is a moderator notification relayed through Redis
+type moderatorNotice struct {
+	Moderators []uuid.UUID     `json:"moderators"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// SetRelay publishes moderator notifications on rdb so every replica running
+// RunModeratorRelay delivers them to its own websocket clients. Without a
+// relay they only reach moderators connected to this instance.
+func (s *SafetyService) SetRelay(rdb *redis.Client) {
+	s.relay = rdb
+}
+
+// notifyModerators sends a websocket message to every connected moderator
+func (s *SafetyService) notifyModerators(ctx context.Context, messageType string, payload interface{}) {
+	var moderators []uuid.UUID
+	err := s.db.WithContext(ctx).Model(&models.User{}).
+		Where("role IN ?", auth.RolesWith(auth.PermReportsModerate)).
+		Pluck("id", &moderators).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to look up moderators to notify",
+			slog.String("message_type", messageType), slog.Any("error", err))
+		return
+	}
+	if s.relay == nil {
+		for _, id := range moderators {
+			s.ws.BroadcastToUser(ctx, id, messageType, payload)
+		}
+		return
+	}
+
+	raw, err := json.Marshal(payload)
+	if err == nil {
+		var data []byte
+		data, err = json.Marshal(moderatorNotice{Moderators: moderators, Type: messageType, Payload: raw})
+		if err == nil {
+			err = s.relay.Publish(ctx, moderatorChannel, data).Err()
+		}
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to publish moderator notification",
+			slog.String("message_type", messageType), slog.Any("error", err))
+	}
+}
+
+// RunModeratorRelay delivers moderator notifications published by any
+// replica, this one included, until ctx is cancelled
+func (s *SafetyService) RunModeratorRelay(ctx context.Context) {
+	if s.relay == nil {
+		return
+	}
+	sub := s.relay.Subscribe(ctx, moderatorChannel)
+	defer sub.Close()
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			var notice moderatorNotice
+			if err := json.Unmarshal([]byte(msg.Payload), &notice); err != nil {
+				slog.ErrorContext(ctx, "dropping malformed moderator notification", slog.Any("error", err))
+				continue
+			}
+			for _, id := range notice.Moderators {
+				s.ws.BroadcastToUser(ctx, id, notice.Type, notice.Payload)
+			}
+		}
+	}
+}
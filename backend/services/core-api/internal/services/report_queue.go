@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReportSort orders the moderator queue
+type ReportSort string
+
+const (
+	// ReportSortPriority lists the highest priority first, oldest first within a priority
+	ReportSortPriority ReportSort = "priority"
+	// ReportSortOldest lists the longest-waiting reports first
+	ReportSortOldest ReportSort = "oldest"
+	// ReportSortNewest lists the most recent reports first
+	ReportSortNewest ReportSort = "newest"
+)
+
+const (
+	defaultReportPageSize = 50
+	maxReportPageSize     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+)
+
+// ReportFilter selects and orders reports for the moderator queue. Zero
+// values mean no filter.
+type ReportFilter struct {
+	Statuses   []models.IncidentStatus
+	Types      []models.IncidentType
+	ReporterID *uuid.UUID
+	ReportedID *uuid.UUID
+	AssigneeID *uuid.UUID
+	// Unassigned selects reports nobody is assigned to
+	Unassigned bool
+	// From and To bound created_at; From is inclusive, To exclusive
+	From *time.Time
+	To   *time.Time
+	// Overdue selects reports that have, or have not, breached their SLA
+	Overdue *bool
+
+	Sort   ReportSort
+	Limit  int
+	Cursor string
+}
+
+// ReportPage is one page of the queue. NextCursor is empty on the last page.
+type ReportPage struct {
+	Reports    []models.SafetyReport `json:"reports"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// reportCursor records the sort key of the last report on a page
+type reportCursor struct {
+	Sort      ReportSort `json:"s"`
+	Priority  int        `json:"p"`
+	CreatedAt time.Time  `json:"t"`
+	ID        uuid.UUID  `json:"id"`
+}
+
+func encodeReportCursor(sort ReportSort, r *models.SafetyReport) string {
+	data, _ := json.Marshal(reportCursor{Sort: sort, Priority: r.Priority, CreatedAt: r.CreatedAt, ID: r.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeReportCursor(sort ReportSort, s string) (*reportCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cur reportCursor
+	if err := json.Unmarshal(data, &cur); err != nil || cur.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+	return &cur, nil
+}
+
+// ListReports returns one page of the moderator queue with evidence,
+// reporter credibility and the holder of any live claim filled in.
+// Pages use keyset pagination, so reports created while a moderator pages
+// through the queue do not shift or repeat entries.
+func (s *SafetyService) ListReports(ctx context.Context, f ReportFilter) (*ReportPage, error) {
+	if f.Sort == "" {
+		f.Sort = ReportSortPriority
+	}
+	switch f.Sort {
+	case ReportSortPriority, ReportSortOldest, ReportSortNewest:
+	default:
+		return nil, ErrInvalidSort
+	}
+	if f.Limit <= 0 {
+		f.Limit = defaultReportPageSize
+	}
+	if f.Limit > maxReportPageSize {
+		f.Limit = maxReportPageSize
+	}
+
+	q := s.db.WithContext(ctx).Model(&models.SafetyReport{}).
+		Preload("Evidence", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		})
+
+	if len(f.Statuses) > 0 {
+		q = q.Where("status IN ?", f.Statuses)
+	}
+	if len(f.Types) > 0 {
+		q = q.Where("type IN ?", f.Types)
+	}
+	if f.ReporterID != nil {
+		q = q.Where("reporter_id = ?", *f.ReporterID)
+	}
+	if f.ReportedID != nil {
+		q = q.Where("reported_id = ?", *f.ReportedID)
+	}
+	if f.AssigneeID != nil {
+		q = q.Where("assignee_id = ?", *f.AssigneeID)
+	}
+	if f.Unassigned {
+		q = q.Where("assignee_id IS NULL")
+	}
+	if f.From != nil {
+		q = q.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("created_at < ?", *f.To)
+	}
+	if f.Overdue != nil {
+		if *f.Overdue {
+			q = q.Where("overdue_at IS NOT NULL")
+		} else {
+			q = q.Where("overdue_at IS NULL")
+		}
+	}
+
+	var cur *reportCursor
+	if f.Cursor != "" {
+		var err error
+		if cur, err = decodeReportCursor(f.Sort, f.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	switch f.Sort {
+	case ReportSortPriority:
+		if cur != nil {
+			q = q.Where("(priority < ? OR (priority = ? AND (created_at, id) > (?, ?)))",
+				cur.Priority, cur.Priority, cur.CreatedAt, cur.ID)
+		}
+		q = q.Order("priority DESC").Order("created_at ASC").Order("id ASC")
+	case ReportSortOldest:
+		if cur != nil {
+			q = q.Where("(created_at, id) > (?, ?)", cur.CreatedAt, cur.ID)
+		}
+		q = q.Order("created_at ASC").Order("id ASC")
+	case ReportSortNewest:
+		if cur != nil {
+			q = q.Where("(created_at, id) < (?, ?)", cur.CreatedAt, cur.ID)
+		}
+		q = q.Order("created_at DESC").Order("id DESC")
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	reports := make([]models.SafetyReport, 0, f.Limit+1)
+	if err := q.Limit(f.Limit + 1).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	page := &ReportPage{Reports: reports}
+	if len(reports) > f.Limit {
+		page.Reports = reports[:f.Limit]
+		page.NextCursor = encodeReportCursor(f.Sort, &page.Reports[f.Limit-1])
+	}
+
+	if len(page.Reports) > 0 {
+		ids := make([]uuid.UUID, 0, len(page.Reports))
+		for i := range page.Reports {
+			ids = append(ids, page.Reports[i].ReporterID)
+		}
+		signals, err := s.reporterCredibility(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range page.Reports {
+			page.Reports[i].ReporterCredibility = signals[page.Reports[i].ReporterID]
+		}
+		if err := s.fillReviewers(ctx, page.Reports); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
@@ -0,0 +1,439 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/websocket"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("response template not found")
+	ErrTemplateExists   = errors.New("a template with this key and locale already exists")
+	ErrInvalidTemplate  = errors.New("invalid response template")
+	ErrInvalidLocale    = errors.New("invalid locale")
+	ErrInvalidVariable  = errors.New("invalid response variable")
+)
+
+// DefaultResponseLocale is used when a template has no version in the
+// requested locale or its base language
+const DefaultResponseLocale = "en"
+
+// reportActionResponseSent is the report history action written for a response
+const reportActionResponseSent = "response_sent"
+
+// maxVariableLength caps a moderator-supplied variable value
+const maxVariableLength = 1000
+
+// Built-in variables, filled from the report. Nothing about the reported
+// user is available to templates.
+const (
+	varReporterName    = "reporter_name"
+	varReportReference = "report_reference"
+	varReportType      = "report_type"
+	varFiledDate       = "filed_date"
+)
+
+var (
+	placeholderPattern  = regexp.MustCompile(`\{\{\s*([a-z][a-z0-9_]*)\s*\}\}`)
+	variableNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	templateKeyPattern  = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,79}$`)
+	localePattern       = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+)
+
+// MissingVariablesError lists the placeholders a response needs values for
+type MissingVariablesError struct {
+	Names []string
+}
+
+func (e *MissingVariablesError) Error() string {
+	return "missing response variables: " + strings.Join(e.Names, ", ")
+}
+
+func (e *MissingVariablesError) Unwrap() error {
+	return ErrInvalidVariable
+}
+
+// ResponseTemplateInput is a new template
+type ResponseTemplateInput struct {
+	Key    string
+	Locale string
+	Title  string
+	Body   string
+}
+
+// ResponseRequest picks the template to send about a report. Locale
+// defaults to DefaultResponseLocale. Variables fill placeholders other than
+// the built-in ones.
+type ResponseRequest struct {
+	Key       string
+	Locale    string
+	Variables map[string]string
+}
+
+// RenderedResponse is a template filled in for one report
+type RenderedResponse struct {
+	TemplateID uuid.UUID `json:"template_id"`
+	Key        string    `json:"key"`
+	Locale     string    `json:"locale"`
+	Message    string    `json:"message"`
+}
+
+// ResponseService manages canned responses and sends them to reporters
+type ResponseService struct {
+	db *gorm.DB
+	ws *websocket.Hub
+}
+
+// NewResponseService creates a new response service
+func NewResponseService(db *gorm.DB, ws *websocket.Hub) *ResponseService {
+	return &ResponseService{
+		db: db,
+		ws: ws,
+	}
+}
+
+// ListTemplates returns templates ordered by key and locale, optionally
+// narrowed to one key or locale
+func (s *ResponseService) ListTemplates(ctx context.Context, key, locale string, includeArchived bool) ([]models.ResponseTemplate, error) {
+	q := s.db.WithContext(ctx).Model(&models.ResponseTemplate{})
+	if key != "" {
+		q = q.Where("key = ?", key)
+	}
+	if locale != "" {
+		normalized, err := normalizeLocale(locale)
+		if err != nil {
+			return nil, err
+		}
+		q = q.Where("locale = ?", normalized)
+	}
+	if !includeArchived {
+		q = q.Where("archived_at IS NULL")
+	}
+
+	templates := []models.ResponseTemplate{}
+	if err := q.Order("key ASC").Order("locale ASC").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		templates[i].Variables, _ = templateVariables(templates[i].Body)
+	}
+	return templates, nil
+}
+
+// CreateTemplate adds a template for a key and locale
+func (s *ResponseService) CreateTemplate(ctx context.Context, actorID uuid.UUID, in ResponseTemplateInput) (*models.ResponseTemplate, error) {
+	if !templateKeyPattern.MatchString(in.Key) {
+		return nil, fmt.Errorf("%w: key must be lowercase letters, digits, '.', '_' or '-'", ErrInvalidTemplate)
+	}
+	locale, err := normalizeLocale(in.Locale)
+	if err != nil {
+		return nil, err
+	}
+	variables, err := validateTemplate(in.Title, in.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &models.ResponseTemplate{
+		ID:        uuid.New(),
+		Key:       in.Key,
+		Locale:    locale,
+		Title:     in.Title,
+		Body:      in.Body,
+		CreatedBy: actorID,
+		UpdatedBy: actorID,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Variables: variables,
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.ResponseTemplate{}).
+			Where("key = ? AND locale = ? AND archived_at IS NULL", template.Key, template.Locale).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrTemplateExists
+		}
+		return tx.Create(template).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// UpdateTemplate replaces a template's title and body. Messages already
+// sent keep the text they were sent with.
+func (s *ResponseService) UpdateTemplate(ctx context.Context, templateID, actorID uuid.UUID, title, body string) (*models.ResponseTemplate, error) {
+	variables, err := validateTemplate(title, body)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := s.activeTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Model(template).Updates(map[string]interface{}{
+		"title":      title,
+		"body":       body,
+		"updated_by": actorID,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		return nil, err
+	}
+	template.Variables = variables
+	return template, nil
+}
+
+// ArchiveTemplate withdraws a template from use
+func (s *ResponseService) ArchiveTemplate(ctx context.Context, templateID, actorID uuid.UUID) error {
+	template, err := s.activeTemplate(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Model(template).Updates(map[string]interface{}{
+		"archived_at": time.Now(),
+		"updated_by":  actorID,
+	}).Error
+}
+
+// Preview renders a response for a report without sending it
+func (s *ResponseService) Preview(ctx context.Context, reportID uuid.UUID, req ResponseRequest) (*RenderedResponse, error) {
+	db := s.db.WithContext(ctx)
+	var report models.SafetyReport
+	err := db.Where("id = ?", reportID).First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return renderResponse(db, &report, req)
+}
+
+// Send renders a response and delivers it to the report's reporter. It is
+// kept with their outcome notifications and recorded in the report's
+// history. Reports claimed by another moderator are refused.
+func (s *ResponseService) Send(ctx context.Context, reportID, actorID uuid.UUID, req ResponseRequest) (*models.ReportNotification, error) {
+	var notification *models.ReportNotification
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		report, err := lockReport(tx, reportID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := checkClaim(report, actorID, now); err != nil {
+			return err
+		}
+		rendered, err := renderResponse(tx, report, req)
+		if err != nil {
+			return err
+		}
+		outcome, err := outcomeOf(tx, report.ID, report.Status)
+		if err != nil {
+			return err
+		}
+
+		notification = &models.ReportNotification{
+			ID:         uuid.New(),
+			UserID:     report.ReporterID,
+			ReportID:   report.ID,
+			Kind:       models.NotificationResponse,
+			Outcome:    outcome,
+			Message:    rendered.Message,
+			CreatedAt:  now,
+			TemplateID: &rendered.TemplateID,
+			SentBy:     &actorID,
+		}
+		if err := tx.Create(notification).Error; err != nil {
+			return err
+		}
+		return recordReportAction(tx, report.ID, actorID, reportActionResponseSent,
+			rendered.Key+" ("+rendered.Locale+")", now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.ws.BroadcastToUser(ctx, notification.UserID, "report_response", notification)
+	return notification, nil
+}
+
+func (s *ResponseService) activeTemplate(ctx context.Context, templateID uuid.UUID) (*models.ResponseTemplate, error) {
+	var template models.ResponseTemplate
+	err := s.db.WithContext(ctx).Where("id = ? AND archived_at IS NULL", templateID).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// renderResponse finds the template for req and fills it in for report
+func renderResponse(db *gorm.DB, report *models.SafetyReport, req ResponseRequest) (*RenderedResponse, error) {
+	template, err := resolveTemplate(db, req.Key, req.Locale)
+	if err != nil {
+		return nil, err
+	}
+	variables, err := responseVariables(db, report, req.Variables)
+	if err != nil {
+		return nil, err
+	}
+	message, err := fillTemplate(template.Body, variables)
+	if err != nil {
+		return nil, err
+	}
+	return &RenderedResponse{
+		TemplateID: template.ID,
+		Key:        template.Key,
+		Locale:     template.Locale,
+		Message:    message,
+	}, nil
+}
+
+// resolveTemplate returns the active template for key in locale, falling
+// back to the locale's base language and then DefaultResponseLocale
+func resolveTemplate(db *gorm.DB, key, locale string) (*models.ResponseTemplate, error) {
+	if locale == "" {
+		locale = DefaultResponseLocale
+	}
+	locale, err := normalizeLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	candidates := []string{locale}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		candidates = append(candidates, base)
+	}
+	if candidates[len(candidates)-1] != DefaultResponseLocale {
+		candidates = append(candidates, DefaultResponseLocale)
+	}
+
+	var templates []models.ResponseTemplate
+	if err := db.Where("key = ? AND locale IN ? AND archived_at IS NULL", key, candidates).
+		Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	for _, want := range candidates {
+		for i := range templates {
+			if templates[i].Locale == want {
+				return &templates[i], nil
+			}
+		}
+	}
+	return nil, ErrTemplateNotFound
+}
+
+// responseVariables merges the built-in variables for report with the
+// moderator's own, which may not replace a built-in
+func responseVariables(db *gorm.DB, report *models.SafetyReport, custom map[string]string) (map[string]string, error) {
+	var reporter models.User
+	if err := db.Select("id", "username", "first_name").Where("id = ?", report.ReporterID).First(&reporter).Error; err != nil {
+		return nil, err
+	}
+	name := reporter.FirstName
+	if name == "" {
+		name = reporter.Username
+	}
+
+	variables := map[string]string{
+		varReporterName:    name,
+		varReportReference: strings.ToUpper(report.ID.String()[:8]),
+		varReportType:      string(report.Type),
+		varFiledDate:       report.CreatedAt.UTC().Format("2006-01-02"),
+	}
+	for k, v := range custom {
+		if _, builtin := variables[k]; builtin {
+			return nil, fmt.Errorf("%w: %s is filled in automatically", ErrInvalidVariable, k)
+		}
+		if !variableNamePattern.MatchString(k) {
+			return nil, fmt.Errorf("%w: %q is not a valid name", ErrInvalidVariable, k)
+		}
+		if len(v) > maxVariableLength {
+			return nil, fmt.Errorf("%w: %s is longer than %d bytes", ErrInvalidVariable, k, maxVariableLength)
+		}
+		variables[k] = v
+	}
+	return variables, nil
+}
+
+// fillTemplate replaces each placeholder in body. Values are inserted as
+// they are and never expanded again.
+func fillTemplate(body string, variables map[string]string) (string, error) {
+	var missing []string
+	seen := make(map[string]bool)
+	message := placeholderPattern.ReplaceAllStringFunc(body, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		if v, ok := variables[name]; ok {
+			return v
+		}
+		if !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+		return placeholder
+	})
+	if len(missing) > 0 {
+		return "", &MissingVariablesError{Names: missing}
+	}
+	return message, nil
+}
+
+// validateTemplate checks a template's title and body and returns the
+// variables the body uses
+func validateTemplate(title, body string) ([]string, error) {
+	if strings.TrimSpace(title) == "" || strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("%w: title and body are required", ErrInvalidTemplate)
+	}
+	return templateVariables(body)
+}
+
+// templateVariables returns the distinct placeholder names in body, in
+// order of first use. Braces that do not form a placeholder are an error.
+func templateVariables(body string) ([]string, error) {
+	if rest := placeholderPattern.ReplaceAllString(body, ""); strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+		return nil, fmt.Errorf("%w: placeholders are written {{name}} using lowercase letters, digits and '_'", ErrInvalidTemplate)
+	}
+	variables := []string{}
+	seen := make(map[string]bool)
+	for _, m := range placeholderPattern.FindAllStringSubmatch(body, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			variables = append(variables, m[1])
+		}
+	}
+	return variables, nil
+}
+
+// normalizeLocale canonicalises a BCP 47 style tag such as "pt_br" to "pt-BR"
+func normalizeLocale(locale string) (string, error) {
+	tag := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	if len(tag) > 35 || !localePattern.MatchString(tag) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidLocale, locale)
+	}
+	parts := strings.Split(tag, "-")
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i])
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "-"), nil
+}
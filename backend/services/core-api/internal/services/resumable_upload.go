@@ -0,0 +1,412 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUploadNotFound       = errors.New("upload not found")
+	ErrUploadExpired        = errors.New("upload expired")
+	ErrUploadOffsetMismatch = errors.New("upload offset does not match")
+	ErrInvalidUploadTarget  = errors.New("set report_id or alert_id, not both")
+	ErrNoActiveAlert        = errors.New("no active emergency alert to attach the upload to")
+	ErrAlertNotFound        = errors.New("alert not found")
+)
+
+// UploadTarget names what a resumable upload is evidence for. With neither ID
+// set, the upload is attached to the user's most recent active emergency alert.
+type UploadTarget struct {
+	ReportID *uuid.UUID
+	AlertID  *uuid.UUID
+	FileName string
+	Type     string
+}
+
+// ResumableMaxBytes is the largest resumable upload accepted
+func (s *EvidenceService) ResumableMaxBytes() int64 {
+	return s.resumableMaxBytes
+}
+
+// CreateUpload starts a resumable upload of length bytes
+func (s *EvidenceService) CreateUpload(ctx context.Context, userID uuid.UUID, role models.Role, length int64, target UploadTarget) (*models.ResumableUpload, error) {
+	if length <= 0 {
+		return nil, ErrEmptyEvidenceFile
+	}
+	if length > s.resumableMaxBytes {
+		return nil, ErrEvidenceTooLarge
+	}
+	if target.ReportID != nil && target.AlertID != nil {
+		return nil, ErrInvalidUploadTarget
+	}
+	if err := checkEvidenceType(target.Type); err != nil {
+		return nil, err
+	}
+
+	db := s.db.WithContext(ctx)
+	switch {
+	case target.ReportID != nil:
+		report, err := s.findReport(ctx, *target.ReportID)
+		if err != nil {
+			return nil, err
+		}
+		if report.ReporterID != userID && !auth.Allowed(role, auth.PermReportsModerate) {
+			return nil, ErrForbidden
+		}
+	case target.AlertID != nil:
+		var alert models.EmergencyAlert
+		err := db.Select("id", "user_id").Where("id = ?", *target.AlertID).First(&alert).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAlertNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if alert.UserID != userID && !auth.Allowed(role, auth.PermReportsModerate) {
+			return nil, ErrForbidden
+		}
+	default:
+		var alert models.EmergencyAlert
+		err := db.Select("id").Where("user_id = ? AND status = ?", userID, "active").
+			Order("created_at DESC").First(&alert).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoActiveAlert
+		}
+		if err != nil {
+			return nil, err
+		}
+		target.AlertID = &alert.ID
+	}
+
+	now := time.Now()
+	upload := &models.ResumableUpload{
+		ID:        uuid.New(),
+		UserID:    userID,
+		ReportID:  target.ReportID,
+		AlertID:   target.AlertID,
+		Length:    length,
+		FileName:  cleanFileName(target.FileName),
+		Type:      target.Type,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.resumableTTL),
+	}
+	if err := db.Create(upload).Error; err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// GetUpload returns one of userID's uploads. Other users' uploads are reported
+// as not found.
+func (s *EvidenceService) GetUpload(ctx context.Context, uploadID, userID uuid.UUID) (*models.ResumableUpload, error) {
+	var upload models.ResumableUpload
+	err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", uploadID, userID).First(&upload).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if upload.CompletedAt == nil && time.Now().After(upload.ExpiresAt) {
+		return nil, ErrUploadExpired
+	}
+	return &upload, nil
+}
+
+// WriteChunk appends body at offset, which must equal the bytes received so
+// far. A chunk cut short by a dropped connection is discarded, so the client
+// resumes from the last complete chunk. Once every byte has arrived the
+// upload is assembled into Evidence.
+func (s *EvidenceService) WriteChunk(ctx context.Context, uploadID, userID uuid.UUID, offset int64, body io.Reader) (*models.ResumableUpload, error) {
+	upload, err := s.GetUpload(ctx, uploadID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.CompletedAt != nil {
+		if offset == upload.Length {
+			return upload, nil
+		}
+		return nil, ErrUploadOffsetMismatch
+	}
+	if offset != upload.Offset {
+		return nil, ErrUploadOffsetMismatch
+	}
+
+	if upload.Offset < upload.Length {
+		if err := s.storeChunk(ctx, upload, body); err != nil {
+			return nil, err
+		}
+	}
+
+	if upload.Offset == upload.Length {
+		if err := s.completeUpload(ctx, upload); err != nil {
+			return nil, err
+		}
+	}
+	return upload, nil
+}
+
+// storeChunk saves one PATCH body as its own blob and advances the offset
+func (s *EvidenceService) storeChunk(ctx context.Context, upload *models.ResumableUpload, body io.Reader) error {
+	body = io.LimitReader(body, upload.Length-upload.Offset)
+
+	contentType := upload.ContentType
+	if upload.Offset == 0 {
+		sniffed, head, err := s.sniff(body)
+		if err != nil {
+			return err
+		}
+		contentType = sniffed
+		body = io.MultiReader(bytes.NewReader(head), body)
+	}
+
+	key := fmt.Sprintf("uploads/%s/%d-%s", upload.ID, upload.Offset, uuid.NewString())
+	counter := &countingReader{r: body}
+	if err := s.store.Put(ctx, key, counter, -1, "application/octet-stream"); err != nil {
+		s.discard(ctx, key)
+		return fmt.Errorf("store chunk: %w", err)
+	}
+	if counter.n == 0 {
+		s.discard(ctx, key)
+		return nil
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ResumableUpload{}).
+			Where("id = ? AND \"offset\" = ? AND completed_at IS NULL", upload.ID, upload.Offset).
+			Updates(map[string]interface{}{
+				"offset":       upload.Offset + counter.n,
+				"content_type": contentType,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Another request wrote this offset first
+			return ErrUploadOffsetMismatch
+		}
+		return tx.Create(&models.UploadChunk{
+			UploadID:   upload.ID,
+			Offset:     upload.Offset,
+			Size:       counter.n,
+			StorageKey: key,
+		}).Error
+	})
+	if err != nil {
+		s.discard(ctx, key)
+		return err
+	}
+
+	upload.Offset += counter.n
+	upload.ContentType = contentType
+	return nil
+}
+
+// completeUpload concatenates the chunks into an evidence blob, records the
+// Evidence row and removes the chunks
+func (s *EvidenceService) completeUpload(ctx context.Context, upload *models.ResumableUpload) error {
+	var chunks []models.UploadChunk
+	if err := s.db.WithContext(ctx).Where("upload_id = ?", upload.ID).Order("\"offset\" ASC").Find(&chunks).Error; err != nil {
+		return err
+	}
+	var next int64
+	for _, c := range chunks {
+		if c.Offset != next {
+			return fmt.Errorf("upload %s is missing bytes at offset %d", upload.ID, next)
+		}
+		next += c.Size
+	}
+	if next != upload.Length {
+		return fmt.Errorf("upload %s has %d of %d bytes", upload.ID, next, upload.Length)
+	}
+
+	now := time.Now()
+	evidence := &models.Evidence{
+		ID:          uuid.New(),
+		ReportID:    upload.ReportID,
+		AlertID:     upload.AlertID,
+		Type:        upload.Type,
+		UploaderID:  &upload.UserID,
+		FileName:    upload.FileName,
+		ContentType: upload.ContentType,
+		Size:        upload.Length,
+		CreatedAt:   now,
+	}
+
+	var done *models.ResumableUpload
+	chunkData := &chunkReader{ctx: ctx, s: s, chunks: chunks}
+	defer chunkData.Close()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the upload so a retried final PATCH cannot create a second Evidence row
+		var locked models.ResumableUpload
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", upload.ID).First(&locked).Error; err != nil {
+			return err
+		}
+		if locked.CompletedAt != nil {
+			done = &locked
+			return nil
+		}
+
+		if err := s.save(ctx, tx, evidence, chunkData); err != nil {
+			return err
+		}
+		return tx.Model(&models.ResumableUpload{}).Where("id = ?", upload.ID).Updates(map[string]interface{}{
+			"evidence_id":  evidence.ID,
+			"completed_at": now,
+		}).Error
+	})
+	if err != nil {
+		if evidence.StorageKey != "" {
+			s.discard(ctx, evidence.StorageKey)
+		}
+		return err
+	}
+	if done != nil {
+		*upload = *done
+		return nil
+	}
+	upload.EvidenceID = &evidence.ID
+	upload.CompletedAt = &now
+
+	slog.InfoContext(ctx, "resumable upload completed",
+		slog.String("upload_id", upload.ID.String()),
+		slog.String("evidence_id", upload.EvidenceID.String()))
+	s.removeChunks(ctx, upload.ID, chunks)
+	return nil
+}
+
+// TerminateUpload cancels an unfinished upload and frees its chunks
+func (s *EvidenceService) TerminateUpload(ctx context.Context, uploadID, userID uuid.UUID) error {
+	upload, err := s.GetUpload(ctx, uploadID, userID)
+	if err != nil && !errors.Is(err, ErrUploadExpired) {
+		return err
+	}
+	if upload != nil && upload.CompletedAt != nil {
+		return nil
+	}
+	return s.purgeUpload(ctx, uploadID)
+}
+
+// PurgeExpiredUploads deletes uploads past their expiry together with any
+// chunks left behind, returning how many were removed
+func (s *EvidenceService) PurgeExpiredUploads(ctx context.Context) (int, error) {
+	var ids []uuid.UUID
+	err := s.db.WithContext(ctx).Model(&models.ResumableUpload{}).
+		Where("expires_at < ?", time.Now()).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	for i, id := range ids {
+		if err := s.purgeUpload(ctx, id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// RunUploadJanitor purges expired uploads every interval until ctx is done
+func (s *EvidenceService) RunUploadJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.PurgeExpiredUploads(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "purging expired uploads failed", slog.Any("error", err))
+			} else if n > 0 {
+				slog.InfoContext(ctx, "purged expired uploads", slog.Int("count", n))
+			}
+		}
+	}
+}
+
+func (s *EvidenceService) purgeUpload(ctx context.Context, uploadID uuid.UUID) error {
+	var chunks []models.UploadChunk
+	if err := s.db.WithContext(ctx).Where("upload_id = ?", uploadID).Find(&chunks).Error; err != nil {
+		return err
+	}
+	s.removeChunks(ctx, uploadID, chunks)
+	return s.db.WithContext(ctx).Where("id = ?", uploadID).Delete(&models.ResumableUpload{}).Error
+}
+
+// removeChunks deletes chunk blobs and rows; failures only leave garbage behind
+func (s *EvidenceService) removeChunks(ctx context.Context, uploadID uuid.UUID, chunks []models.UploadChunk) {
+	for _, c := range chunks {
+		s.discard(ctx, c.StorageKey)
+	}
+	if err := s.db.WithContext(ctx).Where("upload_id = ?", uploadID).Delete(&models.UploadChunk{}).Error; err != nil {
+		slog.WarnContext(ctx, "failed to delete upload chunk rows",
+			slog.String("upload_id", uploadID.String()), slog.Any("error", err))
+	}
+}
+
+// chunkReader reads the chunks of an upload in order, opening one at a time
+type chunkReader struct {
+	ctx    context.Context
+	s      *EvidenceService
+	chunks []models.UploadChunk
+	cur    io.ReadCloser
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if len(r.chunks) == 0 {
+				return 0, io.EOF
+			}
+			rc, err := r.s.store.Open(r.ctx, r.chunks[0].StorageKey)
+			if err != nil {
+				return 0, err
+			}
+			r.cur = rc
+			r.chunks = r.chunks[1:]
+		}
+		n, err := r.cur.Read(p)
+		if errors.Is(err, io.EOF) {
+			r.cur.Close()
+			r.cur = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+// Close releases the chunk currently being read, if any
+func (r *chunkReader) Close() error {
+	if r.cur == nil {
+		return nil
+	}
+	err := r.cur.Close()
+	r.cur = nil
+	return err
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -3,40 +3,147 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
-	"disco/internal/models"
-	"disco/internal/websocket"
+	"disco/core-api/internal/escalation"
+	"disco/core-api/internal/metrics"
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/websocket"
 
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var tracer = otel.Tracer("disco/core-api/internal/services")
+
+var (
+	ErrReportNotFound    = errors.New("report not found")
+	ErrInvalidStatus     = errors.New("invalid status")
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrNoteRequired      = errors.New("a note is required")
 )
 
 // SafetyService handles safety-related operations
 type SafetyService struct {
-	db *gorm.DB
-	ws *websocket.Hub
+	db     *gorm.DB
+	ws     *websocket.Hub
+	limits ReportLimits
+	rules  []escalation.Rule
+	sla    []models.SLATier
+
+	// suspendFor bounds the suspensions escalation rules place
+	suspendFor time.Duration
+
+	assignment ReportAssignment
+	relay      *redis.Client
+	// cache is shared with the sanction service so sanctions issued here
+	// apply at once on this instance
+	cache *restrictionCache
 }
 
-// NewSafetyService creates a new safety service
-func NewSafetyService(db *gorm.DB, ws *websocket.Hub) *SafetyService {
+// NewSafetyService creates a new safety service. rules are evaluated after
+// every report is filed and suspend users for suspendFor; sla sets each new
+// report's review deadline.
+func NewSafetyService(db *gorm.DB, ws *websocket.Hub, limits ReportLimits, rules []escalation.Rule, suspendFor time.Duration, sla []models.SLATier) *SafetyService {
 	return &SafetyService{
-		db: db,
-		ws: ws,
+		db:         db,
+		ws:         ws,
+		limits:     limits,
+		rules:      rules,
+		sla:        sla,
+		suspendFor: suspendFor,
 	}
 }
 
-// CreateSafetyReport creates a new safety report
+// SetSanctions shares the sanction service's restriction cache, which is
+// cleared for each user sanctioned here
+func (s *SafetyService) SetSanctions(sanctions *SanctionService) {
+	s.cache = sanctions.cache
+}
+
+// CreateSafetyReport scores and files a new safety report. Duplicates and reports
+// over the reporter's limits are rejected with a DuplicateReportError or
+// ReportLimitError.
 func (s *SafetyService) CreateSafetyReport(ctx context.Context, report *models.SafetyReport) error {
 	report.ID = uuid.New()
 	report.CreatedAt = time.Now()
+	report.UpdatedAt = report.CreatedAt
 	report.Status = models.IncidentStatusPending
+	// Lifecycle timestamps are the server's to set; a client-supplied
+	// overdue_at would hide the report from the SLA checker
+	report.ResolvedAt = nil
+	report.OverdueAt = nil
+	// Only the assignment policy or a moderator may assign or claim it
+	report.AssigneeID = nil
+	report.AssignedAt = nil
+	report.ClaimedBy = nil
+	report.ClaimExpiresAt = nil
+
+	// Linked evidence is chained after the creation event rather than
+	// inserted with the report
+	links := report.Evidence
+	report.Evidence = nil
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.checkReportLimits(tx, report); err != nil {
+			return err
+		}
+
+		if report.Shadowed {
+			report.Priority = 0
+		} else {
+			priority, err := scoreReport(tx, report, len(links))
+			if err != nil {
+				return err
+			}
+			report.Priority = priority
+		}
+		report.SLADeadline = models.SLADeadline(s.sla, report.Priority, report.CreatedAt)
 
-	return s.db.Transaction(func(tx *gorm.DB) error {
 		// Create the report
 		if err := tx.Create(report).Error; err != nil {
 			return err
 		}
+		event := &models.ReportEvent{
+			ID:        uuid.New(),
+			ReportID:  report.ID,
+			ActorID:   report.ReporterID,
+			ToStatus:  report.Status,
+			CreatedAt: report.CreatedAt,
+		}
+		if err := appendToChain(tx, report.ID, &event.ChainLink, &event.CreatedAt, event.ChainContent); err != nil {
+			return err
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+
+		for i := range links {
+			// Only the link itself is taken from the client
+			evidence := &models.Evidence{
+				ID:        uuid.New(),
+				ReportID:  &report.ID,
+				Type:      links[i].Type,
+				URL:       links[i].URL,
+				CreatedAt: time.Now(),
+			}
+			if err := appendToChain(tx, report.ID, &evidence.ChainLink, &evidence.CreatedAt, evidence.ChainContent); err != nil {
+				return err
+			}
+			if err := tx.Create(evidence).Error; err != nil {
+				return err
+			}
+			report.Evidence = append(report.Evidence, *evidence)
+		}
 
 		// If it's an emergency, create an alert
 		if report.Type == models.IncidentTypeEmergency {
@@ -51,12 +158,29 @@ func (s *SafetyService) CreateSafetyReport(ctx context.Context, report *models.S
 			if err := tx.Create(alert).Error; err != nil {
 				return err
 			}
+			metrics.EmergencyAlerts.WithLabelValues(alert.Type).Inc()
+			slog.WarnContext(ctx, "emergency alert created from report",
+				slog.String("alert_id", alert.ID.String()),
+				slog.String("report_id", report.ID.String()))
 			// Broadcast emergency alert
-			s.ws.BroadcastToUser(report.ReporterID, "emergency_alert", alert)
+			s.ws.BroadcastToUser(ctx, report.ReporterID, "emergency_alert", alert)
 		}
 
 		return nil
 	})
+	if err != nil {
+		report.Evidence = links
+		return err
+	}
+
+	// Escalation and assignment finish even if the reporter disconnects
+	if !report.Shadowed {
+		s.escalate(context.WithoutCancel(ctx), report)
+		if s.autoAssigning() {
+			s.autoAssign(context.WithoutCancel(ctx), report)
+		}
+	}
+	return nil
 }
 
 // AddEmergencyContact adds a new emergency contact for a user
@@ -65,7 +189,7 @@ func (s *SafetyService) AddEmergencyContact(ctx context.Context, contact *models
 	contact.CreatedAt = time.Now()
 	contact.UpdatedAt = time.Now()
 
-	return s.db.Create(contact).Error
+	return s.db.WithContext(ctx).Create(contact).Error
 }
 
 // BlockUser creates a new user block
@@ -73,7 +197,7 @@ func (s *SafetyService) BlockUser(ctx context.Context, block *models.UserBlock)
 	block.ID = uuid.New()
 	block.CreatedAt = time.Now()
 
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Create the block
 		if err := tx.Create(block).Error; err != nil {
 			return err
@@ -92,7 +216,16 @@ func (s *SafetyService) BlockUser(ctx context.Context, block *models.UserBlock)
 }
 
 // TriggerEmergencyAlert creates and broadcasts an emergency alert
-func (s *SafetyService) TriggerEmergencyAlert(ctx context.Context, userID uuid.UUID, location *models.Location) error {
+func (s *SafetyService) TriggerEmergencyAlert(ctx context.Context, userID uuid.UUID, location *models.Location) (err error) {
+	ctx, span := tracer.Start(ctx, "SafetyService.TriggerEmergencyAlert")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	alert := &models.EmergencyAlert{
 		ID:        uuid.New(),
 		UserID:    userID,
@@ -102,26 +235,40 @@ func (s *SafetyService) TriggerEmergencyAlert(ctx context.Context, userID uuid.U
 		CreatedAt: time.Now(),
 	}
 
-	if err := s.db.Create(alert).Error; err != nil {
+	if err := s.db.WithContext(ctx).Create(alert).Error; err != nil {
 		return err
 	}
+	metrics.EmergencyAlerts.WithLabelValues(alert.Type).Inc()
+	span.SetAttributes(attribute.String("alert_id", alert.ID.String()))
+	slog.WarnContext(ctx, "emergency alert triggered", slog.String("alert_id", alert.ID.String()))
 
-	// Fetch user's emergency contacts
+	// Fetch user's emergency contacts. Contacts added while the user is
+	// shadow-restricted are kept but not alerted, so the restriction cannot
+	// be sidestepped by listing someone as a contact.
+	contactsQuery := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	restriction, err := activeRestriction(ctx, s.db, userID)
+	if err != nil {
+		return err
+	}
+	if restriction != nil && restriction.Type == models.SanctionShadowRestriction {
+		contactsQuery = contactsQuery.Where("created_at < ?", restriction.CreatedAt)
+	}
 	var contacts []models.EmergencyContact
-	if err := s.db.Where("user_id = ?", userID).Find(&contacts).Error; err != nil {
+	if err := contactsQuery.Find(&contacts).Error; err != nil {
 		return err
 	}
 
-	// Notify emergency contacts
+	// Notifications outlive the request, so they keep its values and trace but not its cancellation
+	notifyCtx := context.WithoutCancel(ctx)
 	for _, contact := range contacts {
 		if contains(contact.NotifyOn, "emergency") {
 			// In a real implementation, this would integrate with SMS/email service
-			go s.notifyEmergencyContact(contact, alert)
+			go s.notifyEmergencyContact(notifyCtx, contact, alert)
 		}
 	}
 
 	// Broadcast to user's websocket connections
-	s.ws.BroadcastToUser(userID, "emergency_alert", alert)
+	s.ws.BroadcastToUser(ctx, userID, "emergency_alert", alert)
 
 	return nil
 }
@@ -129,36 +276,206 @@ func (s *SafetyService) TriggerEmergencyAlert(ctx context.Context, userID uuid.U
 // GetUserBlocks retrieves all blocks for a user
 func (s *SafetyService) GetUserBlocks(ctx context.Context, userID uuid.UUID) ([]models.UserBlock, error) {
 	var blocks []models.UserBlock
-	err := s.db.Where("blocker_id = ?", userID).Find(&blocks).Error
+	err := s.db.WithContext(ctx).Where("blocker_id = ?", userID).Find(&blocks).Error
 	return blocks, err
 }
 
 // GetEmergencyContacts retrieves all emergency contacts for a user
 func (s *SafetyService) GetEmergencyContacts(ctx context.Context, userID uuid.UUID) ([]models.EmergencyContact, error) {
 	var contacts []models.EmergencyContact
-	err := s.db.Where("user_id = ?", userID).Find(&contacts).Error
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Find(&contacts).Error
 	return contacts, err
 }
 
-// UpdateSafetyReportStatus updates the status of a safety report
-func (s *SafetyService) UpdateSafetyReportStatus(ctx context.Context, reportID uuid.UUID, status models.IncidentStatus) error {
-	result := s.db.Model(&models.SafetyReport{}).
-		Where("id = ?", reportID).
-		Updates(map[string]interface{}{
-			"status":     status,
-			"updated_at": time.Now(),
-		})
+// UpdateSafetyReportStatus moves a report along the review flow and records
+// the change, with the moderator's note, in the report's history. An action
+// may only accompany a resolution; it sanctions the reported user. Reports
+// claimed by another moderator are refused with a ReportClaimedError, and
+// those the moderator filed or is the subject of with ErrConflictOfInterest.
+func (s *SafetyService) UpdateSafetyReportStatus(ctx context.Context, reportID, actorID uuid.UUID, status models.IncidentStatus, note string, action *SanctionAction) error {
+	if !status.Valid() {
+		return ErrInvalidStatus
+	}
+	if action != nil {
+		if status != models.IncidentStatusResolved {
+			return ErrSanctionNotResolved
+		}
+		if err := action.Validate(); err != nil {
+			return err
+		}
+	}
+
+	var notification *models.ReportNotification
+	var sanction *models.UserSanction
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		report, err := lockReport(tx, reportID)
+		if err != nil {
+			return err
+		}
+		if err := checkClaim(report, actorID, time.Now()); err != nil {
+			return err
+		}
+		if !report.Status.CanTransitionTo(status) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, report.Status, status)
+		}
+		// The sanction goes first so the reporter's outcome reflects it
+		if action != nil {
+			sanction = &models.UserSanction{
+				UserID:    report.ReportedID,
+				Type:      action.Type,
+				Reason:    action.Reason,
+				ReportID:  &report.ID,
+				IssuedBy:  &actorID,
+				CreatedAt: time.Now(),
+			}
+			if err := issueSanction(tx, sanction, action.Duration); err != nil {
+				return err
+			}
+		}
+		if notification, err = changeReportStatus(tx, report, actorID, status, note); err != nil {
+			return err
+		}
+		return s.syncClaim(tx, report, actorID, status, notification.CreatedAt)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.deliverOutcome(ctx, notification)
+	if sanction != nil {
+		s.cache.forget(sanction.UserID)
+		enforceSanction(ctx, s.ws, sanction)
+	}
+	return nil
+}
 
-	if result.Error != nil {
-		return result.Error
+// ReopenReport returns a resolved or dismissed report to review. A note
+// explaining why is required.
+func (s *SafetyService) ReopenReport(ctx context.Context, reportID, actorID uuid.UUID, note string) error {
+	if strings.TrimSpace(note) == "" {
+		return ErrNoteRequired
 	}
-	if result.RowsAffected == 0 {
-		return errors.New("report not found")
+
+	var notification *models.ReportNotification
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		report, err := lockReport(tx, reportID)
+		if err != nil {
+			return err
+		}
+		if err := checkConflict(report, actorID); err != nil {
+			return err
+		}
+		if !report.Status.Closed() {
+			return fmt.Errorf("%w: only resolved or dismissed reports can be reopened", ErrInvalidTransition)
+		}
+		if notification, err = changeReportStatus(tx, report, actorID, models.IncidentStatusReviewing, note); err != nil {
+			return err
+		}
+		return s.syncClaim(tx, report, actorID, models.IncidentStatusReviewing, notification.CreatedAt)
+	})
+	if err != nil {
+		return err
 	}
 
+	s.deliverOutcome(ctx, notification)
 	return nil
 }
 
+// GetReportHistory returns a report's status changes, oldest first
+func (s *SafetyService) GetReportHistory(ctx context.Context, reportID uuid.UUID) ([]models.ReportEvent, error) {
+	db := s.db.WithContext(ctx)
+
+	var count int64
+	if err := db.Model(&models.SafetyReport{}).Where("id = ?", reportID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrReportNotFound
+	}
+
+	events := []models.ReportEvent{}
+	err := db.Where("report_id = ?", reportID).Order("created_at ASC").Order("id ASC").Find(&events).Error
+	return events, err
+}
+
+// lockReport loads a report with a row lock held until the transaction ends
+func lockReport(tx *gorm.DB, reportID uuid.UUID) (*models.SafetyReport, error) {
+	var report models.SafetyReport
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", reportID).First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// changeReportStatus writes the new status, its chained report_events row and
+// the reporter's outcome notification, which the caller delivers once the
+// transaction commits
+func changeReportStatus(tx *gorm.DB, report *models.SafetyReport, actorID uuid.UUID, status models.IncidentStatus, note string) (*models.ReportNotification, error) {
+	// Updates writes the new values back into report, so read the old
+	// status first
+	from := report.Status
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":     status,
+		"updated_at": now,
+	}
+	if status.Closed() {
+		updates["resolved_at"] = now
+	} else {
+		updates["resolved_at"] = nil
+	}
+	if err := tx.Model(report).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	event := &models.ReportEvent{
+		ID:         uuid.New(),
+		ReportID:   report.ID,
+		ActorID:    actorID,
+		FromStatus: &from,
+		ToStatus:   status,
+		Note:       note,
+		CreatedAt:  now,
+	}
+	if err := appendToChain(tx, report.ID, &event.ChainLink, &event.CreatedAt, event.ChainContent); err != nil {
+		return nil, err
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return nil, err
+	}
+
+	return recordOutcome(tx, report, status, now)
+}
+
+// recordReportAction appends a chained report_events row for an action taken
+// about a report that leaves its status unchanged
+func recordReportAction(tx *gorm.DB, reportID, actorID uuid.UUID, action, note string, at time.Time) error {
+	report, err := lockReport(tx, reportID)
+	if err != nil {
+		return err
+	}
+
+	status := report.Status
+	event := &models.ReportEvent{
+		ID:         uuid.New(),
+		ReportID:   report.ID,
+		ActorID:    actorID,
+		FromStatus: &status,
+		ToStatus:   status,
+		Action:     action,
+		Note:       note,
+		CreatedAt:  at,
+	}
+	if err := appendToChain(tx, report.ID, &event.ChainLink, &event.CreatedAt, event.ChainContent); err != nil {
+		return err
+	}
+	return tx.Create(event).Error
+}
+
 // Helper function to check if a string slice contains a value
 func contains(slice []string, str string) bool {
 	for _, v := range slice {
@@ -170,7 +487,18 @@ func contains(slice []string, str string) bool {
 }
 
 // Helper function to notify emergency contact (implementation would vary based on notification service)
-func (s *SafetyService) notifyEmergencyContact(contact models.EmergencyContact, alert *models.EmergencyAlert) {
+func (s *SafetyService) notifyEmergencyContact(ctx context.Context, contact models.EmergencyContact, alert *models.EmergencyAlert) {
+	ctx, span := tracer.Start(ctx, "SafetyService.notifyEmergencyContact",
+		trace.WithAttributes(
+			attribute.String("alert_id", alert.ID.String()),
+			attribute.String("contact_id", contact.ID.String()),
+		),
+	)
+	defer span.End()
+
 	// Implementation would integrate with SMS/email service
 	// This is a placeholder for the actual implementation
+	slog.InfoContext(ctx, "notifying emergency contact",
+		slog.String("alert_id", alert.ID.String()),
+		slog.String("contact_id", contact.ID.String()))
 }
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"disco/core-api/internal/metrics"
+	"disco/core-api/internal/models"
+	"disco/core-api/internal/websocket"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAccountSuspended    = errors.New("account suspended")
+	ErrAccountBanned       = errors.New("account banned")
+	ErrSanctionNotFound    = errors.New("sanction not found")
+	ErrInvalidSanction     = errors.New("invalid sanction")
+	ErrSanctionNotResolved = errors.New("an action can only be taken when resolving a report")
+	ErrSanctionLifted      = errors.New("sanction already lifted")
+)
+
+// SanctionAction is what a moderator does to the reported user when
+// resolving a report. Duration is required for suspensions, optional for
+// shadow restrictions and not allowed for warnings and bans. Reason is shown
+// to the sanctioned user and is always required.
+type SanctionAction struct {
+	Type     models.SanctionType
+	Duration time.Duration
+	Reason   string
+}
+
+// Validate checks the action's type, duration and reason
+func (a *SanctionAction) Validate() error {
+	if !a.Type.Valid() {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidSanction, a.Type)
+	}
+	switch {
+	case strings.TrimSpace(a.Reason) == "":
+		return fmt.Errorf("%w: a reason for the user is required", ErrInvalidSanction)
+	case a.Duration < 0:
+		return fmt.Errorf("%w: duration must not be negative", ErrInvalidSanction)
+	case a.Type == models.SanctionSuspension && a.Duration == 0:
+		return fmt.Errorf("%w: a suspension needs a duration", ErrInvalidSanction)
+	case (a.Type == models.SanctionWarning || a.Type == models.SanctionBan) && a.Duration > 0:
+		return fmt.Errorf("%w: a %s takes no duration", ErrInvalidSanction, a.Type)
+	}
+	return nil
+}
+
+// SanctionService reviews and lifts sanctions and tells the enforcement
+// middleware which sanction currently restricts a user
+type SanctionService struct {
+	db *gorm.DB
+	// cache is nil when lookups are not cached
+	cache *restrictionCache
+}
+
+// NewSanctionService creates a new sanction service. Restriction lookups are
+// reused for cacheTTL; zero disables the cache.
+func NewSanctionService(db *gorm.DB, cacheTTL time.Duration) *SanctionService {
+	s := &SanctionService{
+		db: db,
+	}
+	if cacheTTL > 0 {
+		s.cache = newRestrictionCache(cacheTTL)
+	}
+	return s
+}
+
+// Restriction returns the most severe sanction restricting the user now, or
+// nil. Warnings never restrict. The result may be up to the cache TTL old,
+// except that a cached sanction is never returned after it expires.
+func (s *SanctionService) Restriction(ctx context.Context, userID uuid.UUID) (*models.UserSanction, error) {
+	if s.cache == nil {
+		return activeRestriction(ctx, s.db, userID)
+	}
+
+	now := time.Now()
+	if sanction, ok := s.cache.get(userID, now); ok {
+		return sanction, nil
+	}
+	sanction, err := activeRestriction(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.put(userID, sanction, now)
+	return sanction, nil
+}
+
+// ListUserSanctions returns every sanction placed on the user, newest first
+func (s *SanctionService) ListUserSanctions(ctx context.Context, userID uuid.UUID) ([]models.UserSanction, error) {
+	sanctions := []models.UserSanction{}
+	err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&sanctions).Error
+	return sanctions, err
+}
+
+// MySanction is a sanction as the sanctioned user sees it: nothing about who
+// issued it, the report or rule behind it or its review. The ID is kept so
+// the user can appeal it.
+type MySanction struct {
+	ID        uuid.UUID           `json:"id"`
+	Type      models.SanctionType `json:"type"`
+	Reason    string              `json:"reason"`
+	CreatedAt time.Time           `json:"created_at"`
+	// ExpiresAt is when a lifted sanction ended
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func mySanction(sanction *models.UserSanction) MySanction {
+	expires := sanction.ExpiresAt
+	if sanction.LiftedAt != nil {
+		expires = sanction.LiftedAt
+	}
+	return MySanction{
+		ID:        sanction.ID,
+		Type:      sanction.Type,
+		Reason:    sanction.Reason,
+		CreatedAt: sanction.CreatedAt,
+		ExpiresAt: expires,
+	}
+}
+
+// ListOwnSanctions returns the sanctions the user has been told about:
+// shadow restrictions are left out so the user cannot discover them
+func (s *SanctionService) ListOwnSanctions(ctx context.Context, userID uuid.UUID) ([]MySanction, error) {
+	var sanctions []models.UserSanction
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ? AND type <> ?", userID, models.SanctionShadowRestriction).
+		Order("created_at DESC").
+		Find(&sanctions).Error; err != nil {
+		return nil, err
+	}
+	result := make([]MySanction, 0, len(sanctions))
+	for i := range sanctions {
+		result = append(result, mySanction(&sanctions[i]))
+	}
+	return result, nil
+}
+
+// History returns the appeal steps taken on a sanction, oldest first
+func (s *SanctionService) History(ctx context.Context, sanctionID uuid.UUID) ([]models.SanctionEvent, error) {
+	db := s.db.WithContext(ctx)
+	var count int64
+	if err := db.Model(&models.UserSanction{}).Where("id = ?", sanctionID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrSanctionNotFound
+	}
+
+	events := []models.SanctionEvent{}
+	err := db.Where("sanction_id = ?", sanctionID).Order("created_at ASC").Find(&events).Error
+	return events, err
+}
+
+// LiftSanction ends a sanction early. A reason is required.
+func (s *SanctionService) LiftSanction(ctx context.Context, sanctionID, actorID uuid.UUID, reason string) (*models.UserSanction, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrNoteRequired
+	}
+
+	var sanction *models.UserSanction
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if sanction, err = lockSanction(tx, sanctionID); err != nil {
+			return err
+		}
+		if sanction.LiftedAt != nil {
+			return ErrSanctionLifted
+		}
+
+		now := time.Now()
+		sanction.LiftedAt = &now
+		sanction.LiftedBy = &actorID
+		sanction.LiftReason = reason
+		sanction.PendingReview = false
+		return tx.Model(sanction).Updates(map[string]interface{}{
+			"lifted_at":      now,
+			"lifted_by":      actorID,
+			"lift_reason":    reason,
+			"pending_review": false,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.forget(sanction.UserID)
+	slog.InfoContext(ctx, "sanction lifted",
+		slog.String("sanction_id", sanction.ID.String()),
+		slog.String("user_id", sanction.UserID.String()),
+		slog.String("type", string(sanction.Type)))
+	return sanction, nil
+}
+
+// ConfirmSanction upholds an automatic sanction pending review, making the
+// moderator its issuer. A positive duration replaces the automatic expiry.
+func (s *SanctionService) ConfirmSanction(ctx context.Context, sanctionID, actorID uuid.UUID, duration time.Duration) (*models.UserSanction, error) {
+	if duration < 0 {
+		return nil, fmt.Errorf("%w: duration must not be negative", ErrInvalidSanction)
+	}
+
+	var sanction *models.UserSanction
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if sanction, err = lockSanction(tx, sanctionID); err != nil {
+			return err
+		}
+		if sanction.LiftedAt != nil {
+			return ErrSanctionLifted
+		}
+		if !sanction.PendingReview {
+			return fmt.Errorf("%w: sanction is not pending review", ErrInvalidSanction)
+		}
+
+		updates := map[string]interface{}{
+			"pending_review": false,
+			"issued_by":      actorID,
+		}
+		sanction.PendingReview = false
+		sanction.IssuedBy = &actorID
+		if duration > 0 {
+			expires := sanction.CreatedAt.Add(duration)
+			updates["expires_at"] = expires
+			sanction.ExpiresAt = &expires
+		}
+		return tx.Model(sanction).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.cache.forget(sanction.UserID)
+	return sanction, nil
+}
+
+// restrictionCache keeps each user's restriction, including having none,
+// for a short time so the enforcement middleware does not query the
+// database on every request. Changes made on other instances show up once
+// the entry expires.
+type restrictionCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[uuid.UUID]cachedRestriction
+	lastSweep time.Time
+}
+
+type cachedRestriction struct {
+	sanction *models.UserSanction
+	expires  time.Time
+}
+
+func newRestrictionCache(ttl time.Duration) *restrictionCache {
+	return &restrictionCache{
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]cachedRestriction),
+	}
+}
+
+// get returns the cached restriction for userID and whether there was one
+// still usable at now
+func (c *restrictionCache) get(userID uuid.UUID, now time.Time) (*models.UserSanction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[userID]
+	if !ok || !now.Before(entry.expires) {
+		return nil, false
+	}
+	// A sanction that ran out since it was cached must be looked up again
+	if entry.sanction != nil && !entry.sanction.Active(now) {
+		return nil, false
+	}
+	return entry.sanction, true
+}
+
+// put caches the restriction looked up at now. Expired entries are swept at
+// most once per TTL, so the cache only holds recently active users.
+func (c *restrictionCache) put(userID uuid.UUID, sanction *models.UserSanction, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for id, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, id)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[userID] = cachedRestriction{sanction: sanction, expires: now.Add(c.ttl)}
+}
+
+// forget drops the user's entry after one of their sanctions changes
+func (c *restrictionCache) forget(userID uuid.UUID) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, userID)
+}
+
+// lockSanction loads a sanction with a row lock held until the transaction ends
+func lockSanction(tx *gorm.DB, sanctionID uuid.UUID) (*models.UserSanction, error) {
+	var sanction models.UserSanction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", sanctionID).First(&sanction).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSanctionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sanction, nil
+}
+
+func recordSanctionEvent(tx *gorm.DB, sanctionID, actorID uuid.UUID, action, note string, at time.Time) error {
+	return tx.Create(&models.SanctionEvent{
+		ID:         uuid.New(),
+		SanctionID: sanctionID,
+		ActorID:    actorID,
+		Action:     action,
+		Note:       note,
+		CreatedAt:  at,
+	}).Error
+}
+
+// activeRestriction returns the most severe non-warning sanction in force
+// on the user now, or nil
+func activeRestriction(ctx context.Context, db *gorm.DB, userID uuid.UUID) (*models.UserSanction, error) {
+	var sanctions []models.UserSanction
+	err := db.WithContext(ctx).
+		Where("user_id = ? AND type <> ? AND lifted_at IS NULL", userID, models.SanctionWarning).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at DESC").
+		Find(&sanctions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var worst *models.UserSanction
+	for i := range sanctions {
+		if worst == nil || sanctions[i].Type.Severity() > worst.Type.Severity() {
+			worst = &sanctions[i]
+		}
+	}
+	return worst, nil
+}
+
+// lockoutError returns the error to refuse a sign-in with, or nil if the
+// sanction does not lock the user out
+func lockoutError(sanction *models.UserSanction) error {
+	switch {
+	case sanction == nil:
+		return nil
+	case sanction.Type == models.SanctionBan:
+		return ErrAccountBanned
+	case sanction.Type == models.SanctionSuspension:
+		return ErrAccountSuspended
+	}
+	return nil
+}
+
+// issueSanction stores a new sanction lasting duration, or open-ended when
+// duration is zero. Sanctions without an issuer are left pending review.
+func issueSanction(tx *gorm.DB, sanction *models.UserSanction, duration time.Duration) error {
+	sanction.ID = uuid.New()
+	sanction.PendingReview = sanction.IssuedBy == nil
+	if duration > 0 {
+		expires := sanction.CreatedAt.Add(duration)
+		sanction.ExpiresAt = &expires
+	}
+	return tx.Create(sanction).Error
+}
+
+// enforceSanction applies a newly committed sanction to the user's live
+// session: warnings are pushed to them and lockouts close their websocket
+// connections. Shadow restrictions are deliberately silent.
+func enforceSanction(ctx context.Context, ws *websocket.Hub, sanction *models.UserSanction) {
+	metrics.SanctionsIssued.WithLabelValues(string(sanction.Type)).Inc()
+	slog.InfoContext(ctx, "sanction issued",
+		slog.String("sanction_id", sanction.ID.String()),
+		slog.String("user_id", sanction.UserID.String()),
+		slog.String("type", string(sanction.Type)))
+
+	switch sanction.Type {
+	case models.SanctionWarning:
+		ws.BroadcastToUser(ctx, sanction.UserID, "account_warning", mySanction(sanction))
+	case models.SanctionSuspension, models.SanctionBan:
+		ws.DisconnectUser(ctx, sanction.UserID, lockoutError(sanction).Error())
+	}
+}
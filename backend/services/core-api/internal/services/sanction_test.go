@@ -0,0 +1,216 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB builds statements without a database, for code that only writes
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	return db
+}
+
+func TestSanctionActionValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  SanctionAction
+		wantErr bool
+	}{
+		{"warning", SanctionAction{Type: models.SanctionWarning, Reason: "spam"}, false},
+		{"timed suspension", SanctionAction{Type: models.SanctionSuspension, Duration: 72 * time.Hour, Reason: "spam"}, false},
+		{"ban", SanctionAction{Type: models.SanctionBan, Reason: "spam"}, false},
+		{"open-ended shadow restriction", SanctionAction{Type: models.SanctionShadowRestriction, Reason: "spam"}, false},
+		{"timed shadow restriction", SanctionAction{Type: models.SanctionShadowRestriction, Duration: time.Hour, Reason: "spam"}, false},
+		{"unknown type", SanctionAction{Type: "mute", Reason: "spam"}, true},
+		{"suspension without duration", SanctionAction{Type: models.SanctionSuspension, Reason: "spam"}, true},
+		{"negative duration", SanctionAction{Type: models.SanctionSuspension, Duration: -time.Hour, Reason: "spam"}, true},
+		{"warning with duration", SanctionAction{Type: models.SanctionWarning, Duration: time.Hour, Reason: "spam"}, true},
+		{"ban with duration", SanctionAction{Type: models.SanctionBan, Duration: time.Hour, Reason: "spam"}, true},
+		{"no reason", SanctionAction{Type: models.SanctionWarning, Reason: " "}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.action.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSanction) {
+				t.Errorf("Validate() error = %v, want it to wrap %v", err, ErrInvalidSanction)
+			}
+		})
+	}
+}
+
+func TestLockoutError(t *testing.T) {
+	tests := []struct {
+		name     string
+		sanction *models.UserSanction
+		want     error
+	}{
+		{"no sanction", nil, nil},
+		{"warning", &models.UserSanction{Type: models.SanctionWarning}, nil},
+		{"shadow restriction", &models.UserSanction{Type: models.SanctionShadowRestriction}, nil},
+		{"suspension", &models.UserSanction{Type: models.SanctionSuspension}, ErrAccountSuspended},
+		{"ban", &models.UserSanction{Type: models.SanctionBan}, ErrAccountBanned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := lockoutError(tt.sanction)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Errorf("lockoutError() = %v, want %v", err, tt.want)
+			}
+			if tt.sanction != nil && (err != nil) != tt.sanction.Type.LocksOut() {
+				t.Errorf("lockoutError() = %v disagrees with LocksOut() = %v", err, tt.sanction.Type.LocksOut())
+			}
+		})
+	}
+}
+
+func TestIssueSanction(t *testing.T) {
+	db := dryRunDB(t)
+	issued := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	moderator := uuid.New()
+
+	tests := []struct {
+		name        string
+		issuedBy    *uuid.UUID
+		duration    time.Duration
+		wantPending bool
+		wantExpires *time.Time
+	}{
+		{name: "timed by a moderator", issuedBy: &moderator, duration: 48 * time.Hour, wantExpires: ptr(issued.Add(48 * time.Hour))},
+		{name: "open-ended by a moderator", issuedBy: &moderator},
+		{name: "automatic", duration: time.Hour, wantPending: true, wantExpires: ptr(issued.Add(time.Hour))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sanction := &models.UserSanction{
+				UserID:    uuid.New(),
+				Type:      models.SanctionSuspension,
+				Reason:    "harassment",
+				IssuedBy:  tt.issuedBy,
+				CreatedAt: issued,
+			}
+			if err := issueSanction(db, sanction, tt.duration); err != nil {
+				t.Fatalf("issueSanction: %v", err)
+			}
+
+			if sanction.ID == uuid.Nil {
+				t.Error("ID was not set")
+			}
+			if sanction.PendingReview != tt.wantPending {
+				t.Errorf("PendingReview = %v, want %v", sanction.PendingReview, tt.wantPending)
+			}
+			switch {
+			case tt.wantExpires == nil && sanction.ExpiresAt != nil:
+				t.Errorf("ExpiresAt = %v, want nil", sanction.ExpiresAt)
+			case tt.wantExpires != nil && (sanction.ExpiresAt == nil || !sanction.ExpiresAt.Equal(*tt.wantExpires)):
+				t.Errorf("ExpiresAt = %v, want %v", sanction.ExpiresAt, tt.wantExpires)
+			}
+
+			if !sanction.Active(issued) {
+				t.Error("new sanction is not active when issued")
+			}
+			if tt.wantExpires != nil && sanction.Active(*tt.wantExpires) {
+				t.Error("sanction is still active when it expires")
+			}
+		})
+	}
+}
+
+func TestRecordAppealStep(t *testing.T) {
+	db := dryRunDB(t)
+	var created []*models.SanctionEvent
+	if err := db.Callback().Create().After("gorm:create").Register("test:capture", func(tx *gorm.DB) {
+		if e, ok := tx.Statement.Dest.(*models.SanctionEvent); ok {
+			created = append(created, e)
+		}
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	// Sanctions applied directly by a moderator have no report to audit them
+	sanction := &models.UserSanction{ID: uuid.New(), UserID: uuid.New(), Type: models.SanctionSuspension}
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := recordAppealStep(db, sanction, sanction.UserID, actionAppealFiled, "I was hacked", at); err != nil {
+		t.Fatalf("recordAppealStep: %v", err)
+	}
+
+	if len(created) != 1 {
+		t.Fatalf("recorded %d sanction events, want 1", len(created))
+	}
+	e := created[0]
+	if e.ID == uuid.Nil || e.SanctionID != sanction.ID || e.ActorID != sanction.UserID ||
+		e.Action != actionAppealFiled || e.Note != "I was hacked" || !e.CreatedAt.Equal(at) {
+		t.Errorf("recorded %+v, want the appeal filed by the user on the sanction", e)
+	}
+}
+
+func TestRestrictionCache(t *testing.T) {
+	const ttl = 5 * time.Second
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	expires := now.Add(2 * time.Second)
+	suspension := &models.UserSanction{UserID: userID, Type: models.SanctionSuspension, ExpiresAt: &expires}
+
+	tests := []struct {
+		name   string
+		stored *models.UserSanction
+		at     time.Time
+		want   *models.UserSanction
+		hit    bool
+	}{
+		{name: "no restriction is cached", stored: nil, at: now.Add(time.Second), hit: true},
+		{name: "restriction is cached", stored: suspension, at: now.Add(time.Second), want: suspension, hit: true},
+		{name: "entry expires", stored: nil, at: now.Add(ttl)},
+		{name: "sanction ran out", stored: suspension, at: expires},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newRestrictionCache(ttl)
+			c.put(userID, tt.stored, now)
+
+			got, hit := c.get(userID, tt.at)
+			if hit != tt.hit || got != tt.want {
+				t.Errorf("get() = %v, %v, want %v, %v", got, hit, tt.want, tt.hit)
+			}
+		})
+	}
+
+	c := newRestrictionCache(ttl)
+	c.put(userID, suspension, now)
+	c.forget(userID)
+	if _, hit := c.get(userID, now); hit {
+		t.Error("get() hit after forget")
+	}
+
+	// Entries for users who have gone quiet are swept on a later put
+	c.put(userID, nil, now)
+	c.put(uuid.New(), nil, now.Add(ttl))
+	if _, ok := c.entries[userID]; ok {
+		t.Error("expired entry was not swept")
+	}
+
+	var disabled *restrictionCache
+	disabled.forget(userID)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"disco/core-api/internal/auth"
+	"disco/core-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrInvalidRole  = errors.New("invalid role")
+)
+
+// UserService handles user account administration
+type UserService struct {
+	db     *gorm.DB
+	tokens *auth.TokenService
+}
+
+// NewUserService creates a new user service
+func NewUserService(db *gorm.DB, tokens *auth.TokenService) *UserService {
+	return &UserService{
+		db:     db,
+		tokens: tokens,
+	}
+}
+
+// UpdateRole changes a user's role. The user's tokens are revoked because
+// they carry the old role, so a demotion takes effect at once and the user
+// signs in again to pick up the new one.
+func (s *UserService) UpdateRole(ctx context.Context, userID uuid.UUID, role models.Role) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+
+	result := s.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"role":       role,
+			"updated_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return s.tokens.RevokeUser(ctx, userID)
+}
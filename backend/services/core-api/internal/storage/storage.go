@@ -0,0 +1,62 @@
+// Package storage keeps uploaded files in a pluggable blob store
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"disco/core-api/internal/config"
+)
+
+// Supported backends
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// ErrNotFound is returned when a key has no stored object
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores opaque objects under slash-separated keys
+type BlobStore interface {
+	// Put stores size bytes read from r under key, replacing any existing object
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns a reader for the object stored under key
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// Open returns the blob store selected by the configuration
+func Open(ctx context.Context, cfg *config.Config) (BlobStore, error) {
+	switch cfg.StorageBackend {
+	case BackendLocal:
+		return NewLocalStore(cfg.StorageDir)
+	case BackendS3:
+		return NewS3Store(ctx, S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+		})
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+}
+
+// validKey rejects keys that could escape the store's root
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
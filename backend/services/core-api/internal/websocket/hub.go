@@ -1,19 +1,35 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
 	"sync"
 
+	"disco/core-api/internal/metrics"
+	"disco/pkg/logging"
+
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var tracer = otel.Tracer("disco/core-api/internal/websocket")
+
 // Client represents a connected websocket client
 type Client struct {
-	hub  *Hub
-	conn *websocket.Conn
-	send chan []byte
+	hub    *Hub
+	conn   *websocket.Conn
+	send   chan []byte
 	userID uuid.UUID
+	connID uuid.UUID
+	// ctx carries the upgrade request's correlation IDs plus the connection ID for logging
+	ctx context.Context
+	// closeFrame replaces the default going-away frame; it is set before send is closed
+	closeFrame []byte
 }
 
 // Message represents a websocket message
@@ -24,22 +40,107 @@ type Message struct {
 
 // Hub maintains the set of active clients and broadcasts messages
 type Hub struct {
-	clients    map[*Client]bool
+	clients     map[*Client]bool
 	userClients map[uuid.UUID][]*Client
-	broadcast  chan []byte
-	register   chan *Client
-	unregister chan *Client
-	mu         sync.RWMutex
+	broadcast   chan []byte
+	register    chan *Client
+	unregister  chan *Client
+	mu          sync.RWMutex
+
+	// quit asks Run to disconnect every client and return; done is closed once it has
+	quit     chan struct{}
+	done     chan struct{}
+	quitOnce sync.Once
+	// pumps tracks writePumps so Shutdown can wait for close frames to be sent
+	pumps sync.WaitGroup
+
+	upgrader websocket.Upgrader
 }
 
-// NewHub creates a new websocket hub
-func NewHub() *Hub {
+// ErrHubClosed is returned when a connection arrives after Shutdown
+var ErrHubClosed = errors.New("websocket hub is shut down")
+
+// NewHub creates a new websocket hub. checkOrigin decides which browser
+// origins may open a connection.
+func NewHub(checkOrigin func(r *http.Request) bool) *Hub {
 	return &Hub{
 		clients:     make(map[*Client]bool),
 		userClients: make(map[uuid.UUID][]*Client),
 		broadcast:   make(chan []byte),
 		register:    make(chan *Client),
 		unregister:  make(chan *Client),
+		quit:        make(chan struct{}),
+		done:        make(chan struct{}),
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     checkOrigin,
+		},
+	}
+}
+
+// ServeWS upgrades the HTTP connection and registers it with the hub for the given user
+func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request, userID uuid.UUID) error {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		slog.WarnContext(r.Context(), "websocket upgrade failed", slog.Any("error", err))
+		return err
+	}
+
+	// The request context is cancelled once the handler returns, but its
+	// correlation IDs stay useful for the lifetime of the connection
+	connID := uuid.New()
+	ctx := logging.With(context.WithoutCancel(r.Context()), slog.String(logging.ConnIDKey, connID.String()))
+
+	client := &Client{
+		hub:    h,
+		conn:   conn,
+		send:   make(chan []byte, 256),
+		userID: userID,
+		connID: connID,
+		ctx:    ctx,
+	}
+	select {
+	case h.register <- client:
+		slog.InfoContext(ctx, "websocket client connected")
+	case <-h.done:
+		conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+		conn.Close()
+		return ErrHubClosed
+	}
+
+	h.pumps.Add(1)
+	go client.writePump()
+	go client.readPump()
+
+	return nil
+}
+
+// Shutdown disconnects every client with a going-away close frame and waits
+// for their writers to finish or for ctx to expire
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.quitOnce.Do(func() {
+		close(h.quit)
+	})
+
+	select {
+	case <-h.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	pumpsDone := make(chan struct{})
+	go func() {
+		h.pumps.Wait()
+		close(pumpsDone)
+	}()
+
+	select {
+	case <-pumpsDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -52,6 +153,7 @@ func (h *Hub) Run() {
 			h.clients[client] = true
 			h.userClients[client.userID] = append(h.userClients[client.userID], client)
 			h.mu.Unlock()
+			metrics.WebsocketConnections.Inc()
 
 		case client := <-h.unregister:
 			h.mu.Lock()
@@ -59,27 +161,49 @@ func (h *Hub) Run() {
 				delete(h.clients, client)
 				h.removeUserClient(client)
 				close(client.send)
+				metrics.WebsocketConnections.Dec()
 			}
 			h.mu.Unlock()
+			slog.InfoContext(client.ctx, "websocket client disconnected")
 
 		case message := <-h.broadcast:
-			h.mu.RLock()
+			h.mu.Lock()
 			for client := range h.clients {
 				select {
 				case client.send <- message:
 				default:
+					slog.WarnContext(client.ctx, "dropping slow websocket client")
+					metrics.WebsocketMessagesDropped.WithLabelValues("broadcast").Inc()
+					metrics.WebsocketConnections.Dec()
 					close(client.send)
 					delete(h.clients, client)
 					h.removeUserClient(client)
 				}
 			}
-			h.mu.RUnlock()
+			h.mu.Unlock()
+
+		case <-h.quit:
+			h.mu.Lock()
+			slog.Info("closing websocket clients", slog.Int("count", len(h.clients)))
+			for client := range h.clients {
+				close(client.send)
+				delete(h.clients, client)
+				h.removeUserClient(client)
+			}
+			metrics.WebsocketConnections.Set(0)
+			h.mu.Unlock()
+			close(h.done)
+			return
 		}
 	}
 }
 
-// BroadcastToUser sends a message to all connections of a specific user
-func (h *Hub) BroadcastToUser(userID uuid.UUID, messageType string, payload interface{}) {
+// BroadcastToUser sends a message to all connections of a specific user.
+// ctx is only used to correlate the broadcast with the request that caused it.
+func (h *Hub) BroadcastToUser(ctx context.Context, userID uuid.UUID, messageType string, payload interface{}) {
+	ctx, span := tracer.Start(ctx, "websocket.BroadcastToUser")
+	defer span.End()
+
 	message := Message{
 		Type:    messageType,
 		Payload: payload,
@@ -87,22 +211,73 @@ func (h *Hub) BroadcastToUser(userID uuid.UUID, messageType string, payload inte
 
 	data, err := json.Marshal(message)
 	if err != nil {
+		slog.ErrorContext(ctx, "failed to encode websocket message",
+			slog.String("message_type", messageType), slog.Any("error", err))
 		return
 	}
 
-	h.mu.RLock()
+	delivered, dropped := 0, 0
+
+	// Slow clients are dropped here, so the write lock is required
+	h.mu.Lock()
 	if clients, ok := h.userClients[userID]; ok {
 		for _, client := range clients {
 			select {
 			case client.send <- data:
+				delivered++
+				slog.DebugContext(ctx, "websocket message queued",
+					slog.String("message_type", messageType),
+					slog.String(logging.ConnIDKey, client.connID.String()))
 			default:
+				dropped++
+				slog.WarnContext(ctx, "dropping slow websocket client",
+					slog.String("message_type", messageType),
+					slog.String(logging.ConnIDKey, client.connID.String()))
+				metrics.WebsocketMessagesDropped.WithLabelValues(messageType).Inc()
+				metrics.WebsocketConnections.Dec()
 				close(client.send)
 				delete(h.clients, client)
 				h.removeUserClient(client)
 			}
 		}
 	}
-	h.mu.RUnlock()
+	h.mu.Unlock()
+
+	span.SetAttributes(
+		attribute.String("message_type", messageType),
+		attribute.Int("delivered", delivered),
+		attribute.Int("dropped", dropped),
+	)
+	slog.InfoContext(ctx, "websocket broadcast",
+		slog.String("message_type", messageType),
+		slog.String("recipient_id", userID.String()),
+		slog.Int("delivered", delivered),
+		slog.Int("dropped", dropped))
+}
+
+// DisconnectUser closes every connection of a user with a policy-violation
+// close frame carrying reason, and returns how many were closed
+func (h *Hub) DisconnectUser(ctx context.Context, userID uuid.UUID, reason string) int {
+	frame := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason)
+
+	h.mu.Lock()
+	clients := append([]*Client(nil), h.userClients[userID]...)
+	for _, client := range clients {
+		client.closeFrame = frame
+		close(client.send)
+		delete(h.clients, client)
+		h.removeUserClient(client)
+		metrics.WebsocketConnections.Dec()
+	}
+	h.mu.Unlock()
+
+	if len(clients) > 0 {
+		slog.InfoContext(ctx, "disconnected websocket clients",
+			slog.String("user_id", userID.String()),
+			slog.String("reason", reason),
+			slog.Int("count", len(clients)))
+	}
+	return len(clients)
 }
 
 // removeUserClient removes a client from the userClients map
@@ -126,23 +301,30 @@ func (h *Hub) removeUserClient(client *Client) {
 func (c *Client) writePump() {
 	defer func() {
 		c.conn.Close()
+		c.hub.pumps.Done()
 	}()
 
 	for {
 		select {
 		case message, ok := <-c.send:
 			if !ok {
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				frame := c.closeFrame
+				if frame == nil {
+					frame = websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+				}
+				c.conn.WriteMessage(websocket.CloseMessage, frame)
 				return
 			}
 
 			w, err := c.conn.NextWriter(websocket.TextMessage)
 			if err != nil {
+				slog.WarnContext(c.ctx, "websocket write failed", slog.Any("error", err))
 				return
 			}
 			w.Write(message)
 
 			if err := w.Close(); err != nil {
+				slog.WarnContext(c.ctx, "websocket write failed", slog.Any("error", err))
 				return
 			}
 		}
@@ -152,7 +334,10 @@ func (c *Client) writePump() {
 // readPump pumps messages from the websocket connection to the hub
 func (c *Client) readPump() {
 	defer func() {
-		c.hub.unregister <- c
+		select {
+		case c.hub.unregister <- c:
+		case <-c.hub.done:
+		}
 		c.conn.Close()
 	}()
 
@@ -160,7 +345,7 @@ func (c *Client) readPump() {
 		_, _, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				// Log error here
+				slog.WarnContext(c.ctx, "websocket read failed", slog.Any("error", err))
 			}
 			break
 		}
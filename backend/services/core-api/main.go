@@ -1,21 +1,63 @@
 package main
 
 import (
-    "log"
-    "disco/core-api/internal/config"
-    "disco/core-api/internal/server"
+	"log/slog"
+	"os"
+
+	"disco/core-api/internal/config"
+	"disco/core-api/internal/server"
+	"disco/pkg/logging"
 )
 
 func main() {
-    // Load configuration
-    cfg, err := config.Load()
-    if err != nil {
-        log.Fatalf("Failed to load config: %v", err)
-    }
-
-    // Create and start server
-    srv := server.New(cfg)
-    if err := srv.Start(); err != nil {
-        log.Fatalf("Failed to start server: %v", err)
-    }
-}
\ No newline at end of file
+	// Load configuration
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		fatal("failed to load config", err)
+	}
+
+	slog.SetDefault(logging.New("core-api", cfg.Debug))
+
+	// Printing the configuration must work even when it does not validate
+	if len(args) > 0 && args[0] == "config" {
+		if err := runConfig(cfg, args[1:]); err != nil {
+			fatal("config command failed", err)
+		}
+		return
+	}
+
+	// Bundles can be verified on machines without a valid configuration;
+	// the other export commands validate it themselves
+	if len(args) > 0 && args[0] == "export" {
+		if err := runExport(cfg, args[1:]); err != nil {
+			fatal("export command failed", err)
+		}
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fatal("failed to load config", err)
+	}
+
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(cfg, args[1:]); err != nil {
+			fatal("migration failed", err)
+		}
+		return
+	}
+
+	// Create and start server
+	srv, err := server.New(cfg)
+	if err != nil {
+		fatal("failed to create server", err)
+	}
+	if err := srv.Start(); err != nil {
+		fatal("failed to start server", err)
+	}
+}
+
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
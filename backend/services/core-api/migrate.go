@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"disco/core-api/internal/config"
+	"disco/core-api/internal/database"
+	"disco/core-api/internal/migrate"
+	"disco/core-api/migrations"
+)
+
+const migrateUsage = `usage: core-api migrate <command>
+
+commands:
+  up             apply all pending migrations
+  down [N]       revert the last N applied migrations (default 1)
+  status         list migrations and whether they are applied
+  force VERSION  mark VERSION as applied and clean without running SQL`
+
+// runMigrate implements the `core-api migrate` subcommands
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := database.Connect(cfg)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	migrator, err := migrate.New(sqlDB, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+
+	case "status":
+		statuses, current, dirty, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Current version: %d (dirty: %t)\n\n", current, dirty)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+		for _, st := range statuses {
+			state := "pending"
+			if st.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(w, "%06d\t%s\t%s\n", st.Version, st.Name, state)
+		}
+		return w.Flush()
+
+	case "force":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			return err
+		}
+		fmt.Printf("Forced version %d\n", version)
+
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
@@ -0,0 +1,5 @@
+-- Drop role column
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+
+-- Drop enum types
+DROP TYPE IF EXISTS user_role;
@@ -0,0 +1,13 @@
+-- Create enum types
+CREATE TYPE user_role AS ENUM (
+    'user',
+    'moderator',
+    'admin',
+    'support'
+);
+
+-- Add role column to users
+ALTER TABLE users ADD COLUMN role user_role NOT NULL DEFAULT 'user';
+
+-- Create indexes
+CREATE INDEX idx_users_role ON users(role) WHERE role <> 'user';
@@ -0,0 +1,14 @@
+-- Create matches table
+CREATE TABLE matches (
+    id UUID PRIMARY KEY,
+    users UUID[] NOT NULL,
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT two_users CHECK (array_length(users, 1) = 2)
+);
+
+-- Create indexes
+CREATE INDEX idx_matches_users ON matches USING GIN (users);
+CREATE INDEX idx_matches_status ON matches(status);
@@ -0,0 +1,7 @@
+-- Drop queue indexes
+DROP INDEX IF EXISTS idx_safety_reports_type;
+DROP INDEX IF EXISTS idx_safety_reports_queue_age;
+DROP INDEX IF EXISTS idx_safety_reports_queue_priority;
+
+-- Drop priority column
+ALTER TABLE safety_reports DROP COLUMN IF EXISTS priority;
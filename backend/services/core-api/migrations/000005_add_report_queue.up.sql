@@ -0,0 +1,16 @@
+-- Queue priority; higher is reviewed first
+ALTER TABLE safety_reports ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+
+UPDATE safety_reports SET priority = CASE type
+    WHEN 'emergency' THEN 100
+    WHEN 'harassment' THEN 50
+    WHEN 'scam' THEN 40
+    WHEN 'impersonation' THEN 30
+    WHEN 'inappropriate' THEN 20
+    ELSE 10
+END;
+
+-- Keyset pagination indexes for the moderator queue
+CREATE INDEX idx_safety_reports_queue_priority ON safety_reports(priority DESC, created_at, id);
+CREATE INDEX idx_safety_reports_queue_age ON safety_reports(created_at, id);
+CREATE INDEX idx_safety_reports_type ON safety_reports(type);
@@ -0,0 +1,14 @@
+-- Create report_events table; one row per report status change
+CREATE TABLE report_events (
+    id UUID PRIMARY KEY,
+    report_id UUID NOT NULL REFERENCES safety_reports(id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL REFERENCES users(id),
+    from_status incident_status,
+    to_status incident_status NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_report_events_report ON report_events(report_id, created_at);
+CREATE INDEX idx_report_events_actor ON report_events(actor_id);
@@ -0,0 +1,13 @@
+-- Drop upload columns
+DROP INDEX IF EXISTS idx_evidence_storage_key;
+
+ALTER TABLE evidence
+    DROP COLUMN IF EXISTS storage_key,
+    DROP COLUMN IF EXISTS sha256,
+    DROP COLUMN IF EXISTS size,
+    DROP COLUMN IF EXISTS content_type,
+    DROP COLUMN IF EXISTS file_name,
+    DROP COLUMN IF EXISTS uploader_id;
+
+UPDATE evidence SET url = '' WHERE url IS NULL;
+ALTER TABLE evidence ALTER COLUMN url SET NOT NULL;
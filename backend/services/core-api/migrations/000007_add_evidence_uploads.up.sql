@@ -0,0 +1,12 @@
+-- Uploaded evidence lives in the blob store; url is kept for linked evidence
+ALTER TABLE evidence ALTER COLUMN url DROP NOT NULL;
+
+ALTER TABLE evidence
+    ADD COLUMN uploader_id UUID REFERENCES users(id),
+    ADD COLUMN file_name VARCHAR(255),
+    ADD COLUMN content_type VARCHAR(100),
+    ADD COLUMN size BIGINT,
+    ADD COLUMN sha256 CHAR(64),
+    ADD COLUMN storage_key TEXT;
+
+CREATE UNIQUE INDEX idx_evidence_storage_key ON evidence(storage_key) WHERE storage_key IS NOT NULL;
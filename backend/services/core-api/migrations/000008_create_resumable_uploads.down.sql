@@ -0,0 +1,10 @@
+-- Drop tables
+DROP TABLE IF EXISTS resumable_upload_chunks;
+DROP TABLE IF EXISTS resumable_uploads;
+
+-- Evidence belongs to reports only
+DROP INDEX IF EXISTS idx_evidence_alert;
+DELETE FROM evidence WHERE report_id IS NULL;
+ALTER TABLE evidence DROP CONSTRAINT IF EXISTS evidence_has_owner;
+ALTER TABLE evidence DROP COLUMN IF EXISTS alert_id;
+ALTER TABLE evidence ALTER COLUMN report_id SET NOT NULL;
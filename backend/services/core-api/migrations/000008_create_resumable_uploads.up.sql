@@ -0,0 +1,37 @@
+-- Evidence may belong to an emergency alert instead of a report
+ALTER TABLE evidence ALTER COLUMN report_id DROP NOT NULL;
+ALTER TABLE evidence ADD COLUMN alert_id UUID REFERENCES emergency_alerts(id) ON DELETE CASCADE;
+ALTER TABLE evidence ADD CONSTRAINT evidence_has_owner CHECK (report_id IS NOT NULL OR alert_id IS NOT NULL);
+
+-- Create resumable_uploads table; one row per tus upload
+CREATE TABLE resumable_uploads (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    report_id UUID REFERENCES safety_reports(id) ON DELETE CASCADE,
+    alert_id UUID REFERENCES emergency_alerts(id) ON DELETE CASCADE,
+    length BIGINT NOT NULL,
+    "offset" BIGINT NOT NULL DEFAULT 0,
+    file_name VARCHAR(255),
+    type VARCHAR(50),
+    content_type VARCHAR(100),
+    evidence_id UUID REFERENCES evidence(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT resumable_upload_has_owner CHECK (report_id IS NOT NULL OR alert_id IS NOT NULL),
+    CONSTRAINT resumable_upload_offset CHECK ("offset" >= 0 AND "offset" <= length)
+);
+
+-- Create resumable_upload_chunks table; each PATCH is stored as one blob
+CREATE TABLE resumable_upload_chunks (
+    upload_id UUID NOT NULL REFERENCES resumable_uploads(id) ON DELETE CASCADE,
+    "offset" BIGINT NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key TEXT NOT NULL,
+    PRIMARY KEY (upload_id, "offset")
+);
+
+-- Create indexes
+CREATE INDEX idx_evidence_alert ON evidence(alert_id);
+CREATE INDEX idx_resumable_uploads_user ON resumable_uploads(user_id);
+CREATE INDEX idx_resumable_uploads_expires ON resumable_uploads(expires_at) WHERE completed_at IS NULL;
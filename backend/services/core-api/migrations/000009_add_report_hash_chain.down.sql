@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_evidence_chain;
+DROP INDEX IF EXISTS idx_report_events_chain;
+
+ALTER TABLE evidence
+    DROP COLUMN hash,
+    DROP COLUMN prev_hash,
+    DROP COLUMN chain_seq;
+
+ALTER TABLE report_events
+    DROP COLUMN hash,
+    DROP COLUMN prev_hash,
+    DROP COLUMN chain_seq;
+
+ALTER TABLE safety_reports
+    DROP COLUMN chain_head,
+    DROP COLUMN chain_length;
@@ -0,0 +1,18 @@
+-- Tamper-evident hash chain over each report's events and evidence.
+-- Rows written before this migration stay unchained.
+ALTER TABLE safety_reports
+    ADD COLUMN chain_length BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN chain_head CHAR(64);
+
+ALTER TABLE report_events
+    ADD COLUMN chain_seq BIGINT,
+    ADD COLUMN prev_hash CHAR(64),
+    ADD COLUMN hash CHAR(64);
+
+ALTER TABLE evidence
+    ADD COLUMN chain_seq BIGINT,
+    ADD COLUMN prev_hash CHAR(64),
+    ADD COLUMN hash CHAR(64);
+
+CREATE UNIQUE INDEX idx_report_events_chain ON report_events(report_id, chain_seq) WHERE chain_seq IS NOT NULL;
+CREATE UNIQUE INDEX idx_evidence_chain ON evidence(report_id, chain_seq) WHERE chain_seq IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_safety_reports_duplicate;
+DROP INDEX IF EXISTS idx_safety_reports_reporter_recent;
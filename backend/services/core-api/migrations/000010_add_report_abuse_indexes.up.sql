@@ -0,0 +1,3 @@
+-- Duplicate and rate-limit checks look up a reporter's recent reports
+CREATE INDEX idx_safety_reports_reporter_recent ON safety_reports(reporter_id, created_at);
+CREATE INDEX idx_safety_reports_duplicate ON safety_reports(reporter_id, reported_id, type, created_at);
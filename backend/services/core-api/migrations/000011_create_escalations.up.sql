@@ -0,0 +1,14 @@
+-- One row per escalation rule firing; also stops a rule firing again for
+-- the same user within its window
+CREATE TABLE report_escalations (
+    id UUID PRIMARY KEY,
+    rule VARCHAR(100) NOT NULL,
+    reported_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    report_id UUID NOT NULL REFERENCES safety_reports(id) ON DELETE CASCADE,
+    reports INTEGER NOT NULL,
+    distinct_reporters INTEGER NOT NULL,
+    actions TEXT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_report_escalations_recent ON report_escalations(reported_id, rule, created_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_safety_reports_sla;
+
+ALTER TABLE safety_reports
+    DROP COLUMN overdue_at,
+    DROP COLUMN sla_deadline;
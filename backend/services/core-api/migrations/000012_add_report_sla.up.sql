@@ -0,0 +1,7 @@
+-- Review deadlines; overdue_at is set by the SLA checker on breach
+ALTER TABLE safety_reports
+    ADD COLUMN sla_deadline TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN overdue_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_safety_reports_sla ON safety_reports(sla_deadline)
+    WHERE overdue_at IS NULL AND status IN ('pending', 'reviewing');
@@ -0,0 +1,13 @@
+-- Outcome messages for reporters, kept until read so offline users see them
+CREATE TABLE report_notifications (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    report_id UUID NOT NULL REFERENCES safety_reports(id) ON DELETE CASCADE,
+    outcome VARCHAR(20) NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    read_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_report_notifications_report ON report_notifications(report_id, created_at);
+CREATE INDEX idx_report_notifications_unread ON report_notifications(user_id) WHERE read_at IS NULL;
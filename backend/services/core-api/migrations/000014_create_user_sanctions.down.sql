@@ -0,0 +1,3 @@
+ALTER TABLE safety_reports DROP COLUMN IF EXISTS shadowed;
+
+DROP TABLE IF EXISTS user_sanctions;
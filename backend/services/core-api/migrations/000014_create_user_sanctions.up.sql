@@ -0,0 +1,25 @@
+-- Sanctions restrict a user's account: a warning, a suspension for a set
+-- time, a ban or a shadow restriction. Automatic ones have no issuer and
+-- stay pending review until a moderator confirms or lifts them.
+CREATE TABLE user_sanctions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL
+        CHECK (type IN ('warning', 'suspension', 'ban', 'shadow_restriction')),
+    reason TEXT NOT NULL,
+    report_id UUID REFERENCES safety_reports(id) ON DELETE SET NULL,
+    escalation_rule VARCHAR(100),
+    issued_by UUID REFERENCES users(id),
+    pending_review BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    lifted_at TIMESTAMP WITH TIME ZONE,
+    lifted_by UUID REFERENCES users(id),
+    lift_reason TEXT
+);
+
+CREATE INDEX idx_user_sanctions_active ON user_sanctions(user_id) WHERE lifted_at IS NULL;
+CREATE INDEX idx_user_sanctions_user ON user_sanctions(user_id, created_at);
+
+-- Reports filed by shadow-restricted users are kept but carry no weight
+ALTER TABLE safety_reports ADD COLUMN shadowed BOOLEAN NOT NULL DEFAULT false;
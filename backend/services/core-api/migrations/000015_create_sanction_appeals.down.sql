@@ -0,0 +1,3 @@
+ALTER TABLE report_events DROP COLUMN IF EXISTS action;
+
+DROP TABLE IF EXISTS sanction_appeals;
@@ -0,0 +1,19 @@
+-- One appeal per sanction, decided by a moderator other than the issuer
+CREATE TABLE sanction_appeals (
+    id UUID PRIMARY KEY,
+    sanction_id UUID NOT NULL UNIQUE REFERENCES user_sanctions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    statement TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'upheld', 'reduced', 'overturned')),
+    reviewer_id UUID REFERENCES users(id),
+    decision_note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    decided_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_sanction_appeals_pending ON sanction_appeals(created_at) WHERE status = 'pending';
+CREATE INDEX idx_sanction_appeals_user ON sanction_appeals(user_id, created_at);
+
+-- Report history entries that record an action rather than a status change
+ALTER TABLE report_events ADD COLUMN action VARCHAR(40);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS case_events;
+DROP TABLE IF EXISTS case_notes;
+DROP TABLE IF EXISTS case_links;
+DROP TABLE IF EXISTS cases;
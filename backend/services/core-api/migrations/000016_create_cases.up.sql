@@ -0,0 +1,57 @@
+-- Cases group the reports, alerts and sanctions of one investigation
+CREATE TABLE cases (
+    id UUID PRIMARY KEY,
+    title VARCHAR(200) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'investigating', 'closed')),
+    subject_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    owner_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_cases_status ON cases(status, updated_at);
+CREATE INDEX idx_cases_owner ON cases(owner_id) WHERE owner_id IS NOT NULL;
+CREATE INDEX idx_cases_subject ON cases(subject_id) WHERE subject_id IS NOT NULL;
+
+-- Each link points at exactly one record, and a record is in at most one case
+CREATE TABLE case_links (
+    id UUID PRIMARY KEY,
+    case_id UUID NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+    report_id UUID REFERENCES safety_reports(id) ON DELETE CASCADE,
+    alert_id UUID REFERENCES emergency_alerts(id) ON DELETE CASCADE,
+    sanction_id UUID REFERENCES user_sanctions(id) ON DELETE CASCADE,
+    added_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (num_nonnulls(report_id, alert_id, sanction_id) = 1)
+);
+
+CREATE INDEX idx_case_links_case ON case_links(case_id);
+CREATE UNIQUE INDEX idx_case_links_report ON case_links(report_id) WHERE report_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_case_links_alert ON case_links(alert_id) WHERE alert_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_case_links_sanction ON case_links(sanction_id) WHERE sanction_id IS NOT NULL;
+
+-- Internal notes are visible to staff only
+CREATE TABLE case_notes (
+    id UUID PRIMARY KEY,
+    case_id UUID NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id),
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_case_notes_case ON case_notes(case_id, created_at);
+
+-- Changes made to a case itself, for its timeline
+CREATE TABLE case_events (
+    id UUID PRIMARY KEY,
+    case_id UUID NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL REFERENCES users(id),
+    action VARCHAR(40) NOT NULL,
+    detail TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_case_events_case ON case_events(case_id, created_at);
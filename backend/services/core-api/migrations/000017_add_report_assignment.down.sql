@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_report_events_decisions;
+DROP INDEX IF EXISTS idx_safety_reports_assignee;
+
+ALTER TABLE safety_reports
+    DROP COLUMN claim_expires_at,
+    DROP COLUMN claimed_by,
+    DROP COLUMN assigned_at,
+    DROP COLUMN assignee_id;
@@ -0,0 +1,14 @@
+-- Moderator assignment and the review claim lock. A claim is live until
+-- claim_expires_at; an expired claim is simply ignored.
+ALTER TABLE safety_reports
+    ADD COLUMN assignee_id UUID REFERENCES users(id),
+    ADD COLUMN assigned_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN claimed_by UUID REFERENCES users(id),
+    ADD COLUMN claim_expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_safety_reports_assignee ON safety_reports(assignee_id)
+    WHERE status IN ('pending', 'reviewing');
+
+-- Moderator throughput is read from decisions in report history
+CREATE INDEX idx_report_events_decisions ON report_events(actor_id, created_at)
+    WHERE to_status IN ('resolved', 'dismissed');
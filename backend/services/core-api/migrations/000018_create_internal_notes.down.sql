@@ -0,0 +1,15 @@
+CREATE TABLE case_notes (
+    id UUID PRIMARY KEY,
+    case_id UUID NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id),
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_case_notes_case ON case_notes(case_id, created_at);
+
+-- Report notes and thread structure have no place to go
+INSERT INTO case_notes (id, case_id, author_id, body, created_at)
+    SELECT id, case_id, author_id, body, created_at FROM internal_notes WHERE case_id IS NOT NULL;
+
+DROP TABLE IF EXISTS internal_notes;
@@ -0,0 +1,19 @@
+-- Threaded staff-only notes on a report or a case; they replace case_notes
+CREATE TABLE internal_notes (
+    id UUID PRIMARY KEY,
+    report_id UUID REFERENCES safety_reports(id) ON DELETE CASCADE,
+    case_id UUID REFERENCES cases(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES internal_notes(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id),
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (num_nonnulls(report_id, case_id) = 1)
+);
+
+CREATE INDEX idx_internal_notes_report ON internal_notes(report_id, created_at) WHERE report_id IS NOT NULL;
+CREATE INDEX idx_internal_notes_case ON internal_notes(case_id, created_at) WHERE case_id IS NOT NULL;
+
+INSERT INTO internal_notes (id, case_id, author_id, body, created_at)
+    SELECT id, case_id, author_id, body, created_at FROM case_notes;
+
+DROP TABLE case_notes;
@@ -0,0 +1,8 @@
+DELETE FROM report_notifications WHERE kind = 'response';
+
+ALTER TABLE report_notifications
+    DROP COLUMN sent_by,
+    DROP COLUMN template_id,
+    DROP COLUMN kind;
+
+DROP TABLE IF EXISTS response_templates;
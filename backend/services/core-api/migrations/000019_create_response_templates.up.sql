@@ -0,0 +1,23 @@
+-- Canned responses moderators send to reporters. A key has one body per
+-- locale; archived templates stay for the messages already sent from them.
+CREATE TABLE response_templates (
+    id UUID PRIMARY KEY,
+    key VARCHAR(80) NOT NULL,
+    locale VARCHAR(35) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    updated_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    archived_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX idx_response_templates_key ON response_templates(key, locale) WHERE archived_at IS NULL;
+
+-- Reporter notifications are now either outcomes or sent responses
+ALTER TABLE report_notifications
+    ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'outcome'
+        CHECK (kind IN ('outcome', 'response')),
+    ADD COLUMN template_id UUID REFERENCES response_templates(id),
+    ADD COLUMN sent_by UUID REFERENCES users(id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS sanction_events;
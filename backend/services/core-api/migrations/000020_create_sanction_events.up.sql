@@ -0,0 +1,21 @@
+-- Appeal steps taken on a sanction, whether or not it came from a report
+CREATE TABLE sanction_events (
+    id UUID PRIMARY KEY,
+    sanction_id UUID NOT NULL REFERENCES user_sanctions(id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL REFERENCES users(id),
+    action VARCHAR(40) NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sanction_events_sanction ON sanction_events(sanction_id, created_at);
+
+-- Backfill the appeals filed and decided before the history existed
+INSERT INTO sanction_events (id, sanction_id, actor_id, action, note, created_at)
+SELECT gen_random_uuid(), sanction_id, user_id, 'appeal_filed', statement, created_at
+FROM sanction_appeals;
+
+INSERT INTO sanction_events (id, sanction_id, actor_id, action, note, created_at)
+SELECT gen_random_uuid(), sanction_id, reviewer_id, 'appeal_' || status, decision_note, decided_at
+FROM sanction_appeals
+WHERE decided_at IS NOT NULL AND reviewer_id IS NOT NULL;
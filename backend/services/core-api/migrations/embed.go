@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL migration files into the core-api binary
+package migrations
+
+import "embed"
+
+// FS holds every NNNNNN_name.{up,down}.sql file in this directory
+//
+//go:embed *.sql
+var FS embed.FS
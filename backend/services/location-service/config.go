@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	sharedconfig "disco/pkg/config"
+	"disco/pkg/cors"
+	"disco/pkg/tracing"
+)
+
+// Config is loaded from defaults, then the YAML file given by --config or
+// CONFIG_FILE, then environment variables, then command-line flags
+type Config struct {
+	ServerAddr        string        `yaml:"server_addr" env:"SERVER_ADDR" flag:"addr" default:":8081"`
+	RedisURL          string        `yaml:"redis_url" env:"REDIS_URL" flag:"redis-url" default:"redis://localhost:6379"`
+	Debug             bool          `yaml:"debug" env:"DEBUG" flag:"debug" default:"false"`
+	LocationTTL       time.Duration `yaml:"location_ttl" env:"LOCATION_TTL" flag:"location-ttl" default:"24h" usage:"how long a stored location stays in Redis"`
+	MaxMessageBytes   int64         `yaml:"max_message_bytes" env:"MAX_MESSAGE_BYTES" flag:"max-message-bytes" default:"4096" usage:"largest websocket message accepted from a client"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" flag:"read-header-timeout" default:"10s"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" default:"15s"`
+
+	// Browser origins allowed to open websocket connections
+	CORSAllowedOrigins []string `yaml:"cors_allowed_origins" env:"CORS_ALLOWED_ORIGINS" flag:"cors-allowed-origins" default:"http://localhost:3000" usage:"origins allowed to connect; https://*.example.com matches subdomains"`
+	CORSRouteOrigins   []string `yaml:"cors_route_origins" env:"CORS_ROUTE_ORIGINS" flag:"cors-route-origins" usage:"per-route origin overrides as /prefix=origin origin"`
+
+	// Tracing
+	TracingExporter    string  `yaml:"tracing_exporter" env:"TRACING_EXPORTER" flag:"tracing-exporter" default:"none" usage:"span exporter: none, stdout, file or otlp"`
+	TracingEndpoint    string  `yaml:"tracing_endpoint" env:"TRACING_ENDPOINT" flag:"tracing-endpoint" usage:"OTLP/HTTP collector address"`
+	TracingInsecure    bool    `yaml:"tracing_insecure" env:"TRACING_INSECURE" flag:"tracing-insecure" default:"false"`
+	TracingFile        string  `yaml:"tracing_file" env:"TRACING_FILE" flag:"tracing-file" usage:"span output file for the file exporter"`
+	TracingSampleRatio float64 `yaml:"tracing_sample_ratio" env:"TRACING_SAMPLE_RATIO" flag:"tracing-sample-ratio" default:"1"`
+}
+
+// LoadConfig builds the configuration from every layer and returns the
+// remaining command-line arguments
+func LoadConfig(args []string) (*Config, []string, error) {
+	cfg := &Config{}
+	rest, err := sharedconfig.Load(cfg, "location-service", args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, rest, nil
+}
+
+// Validate checks limits and refuses default secrets unless Debug is on
+func (c *Config) Validate() error {
+	var problems []string
+
+	if !c.Debug {
+		if insecure := sharedconfig.InsecureSecrets(c); len(insecure) > 0 {
+			problems = append(problems, fmt.Sprintf("default or empty secrets: %s (set them or enable debug)", strings.Join(insecure, ", ")))
+		}
+	}
+	if c.ServerAddr == "" {
+		problems = append(problems, "server_addr is required")
+	}
+	if c.RedisURL == "" {
+		problems = append(problems, "redis_url is required")
+	}
+	if c.LocationTTL <= 0 {
+		problems = append(problems, "location_ttl must be positive")
+	}
+	if c.MaxMessageBytes < 1 {
+		problems = append(problems, "max_message_bytes must be positive")
+	}
+	if c.ReadHeaderTimeout <= 0 {
+		problems = append(problems, "read_header_timeout must be positive")
+	}
+	if c.ShutdownTimeout <= 0 {
+		problems = append(problems, "shutdown_timeout must be positive")
+	}
+	if _, err := c.CORSPolicy(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if !tracing.ValidExporter(c.TracingExporter) {
+		problems = append(problems, "tracing_exporter must be one of none, stdout, file, otlp")
+	}
+	if c.TracingExporter == tracing.ExporterFile && c.TracingFile == "" {
+		problems = append(problems, "tracing_file is required by the file exporter")
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		problems = append(problems, "tracing_sample_ratio must be between 0 and 1")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// TracingOptions returns the exporter settings for tracing.Setup
+func (c *Config) TracingOptions() tracing.Options {
+	return tracing.Options{
+		Exporter:    c.TracingExporter,
+		Endpoint:    c.TracingEndpoint,
+		Insecure:    c.TracingInsecure,
+		File:        c.TracingFile,
+		SampleRatio: c.TracingSampleRatio,
+	}
+}
+
+// CORSPolicy builds the origin policy applied to websocket upgrades
+func (c *Config) CORSPolicy() (*cors.Policy, error) {
+	policy, err := cors.New(cors.Options{AllowedOrigins: c.CORSAllowedOrigins})
+	if err != nil {
+		return nil, err
+	}
+	return policy.ParseRoutes(c.CORSRouteOrigins)
+}
+
+// runConfigCommand implements `location-service config print [--redacted]`
+func runConfigCommand(cfg *Config, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New("usage: location-service config print [--redacted]")
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redacted := fs.Bool("redacted", false, "mask secrets and URL passwords")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if err := sharedconfig.Print(os.Stdout, cfg, *redacted); err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "\nwarning: %v\n", err)
+	}
+	return nil
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,10 +13,22 @@ import (
 	"syscall"
 	"time"
 
+	"disco/pkg/logging"
+	"disco/pkg/tracing"
+
 	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("location-service")
+
 type Location struct {
 	UserID    string    `json:"userId"`
 	Latitude  float64   `json:"latitude"`
@@ -23,77 +37,99 @@ type Location struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// client is a connected websocket along with its logging context
+type client struct {
+	userID string
+	ctx    context.Context
+}
+
 type Server struct {
-	clients    map[*websocket.Conn]string // websocket -> userId
+	clients    map[*websocket.Conn]*client
 	register   chan *websocket.Conn
 	unregister chan *websocket.Conn
 	broadcast  chan []byte
 	upgrader   websocket.Upgrader
 	redis      *redis.Client
+	config     *Config
 	mu         sync.RWMutex
 }
 
-func NewServer() *Server {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     os.Getenv("LOCATION_REDIS_URL"),
-		Password: os.Getenv("LOCATION_REDIS_PASSWORD"),
-		DB:       0,
-	})
+func NewServer(cfg *Config) (*Server, error) {
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	origins, err := cfg.CORSPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	rdb := redis.NewClient(opts)
+	rdb.AddHook(redisMetrics)
+	rdb.AddHook(tracing.NewRedisHook())
 
 	return &Server{
-		clients:    make(map[*websocket.Conn]string),
+		clients:    make(map[*websocket.Conn]*client),
 		register:   make(chan *websocket.Conn),
 		unregister: make(chan *websocket.Conn),
 		broadcast:  make(chan []byte),
 		upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return true // TODO: Add proper origin check
-			},
+			CheckOrigin: origins.CheckOrigin,
 		},
-		redis: rdb,
-	}
+		redis:  rdb,
+		config: cfg,
+	}, nil
 }
 
 func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
-	ws, err := s.upgrader.Upgrade(w, r, nil)
+	requestID := r.Header.Get("X-Request-ID")
+	if requestID == "" || len(requestID) > 128 {
+		requestID = newID()
+	}
+	// Continue the caller's trace if the upgrade request carries one
+	ctx := otel.GetTextMapPropagator().Extract(context.WithoutCancel(r.Context()), propagation.HeaderCarrier(r.Header))
+	ctx = logging.With(ctx,
+		slog.String(logging.RequestIDKey, requestID),
+		slog.String(logging.ConnIDKey, newID()),
+	)
+
+	ws, err := s.upgrader.Upgrade(w, r, http.Header{"X-Request-ID": {requestID}})
 	if err != nil {
-		log.Printf("Failed to upgrade connection: %v", err)
+		slog.WarnContext(ctx, "websocket upgrade failed", slog.Any("error", err))
 		return
 	}
 	defer ws.Close()
 
 	userID := r.URL.Query().Get("userId")
 	if userID == "" {
-		log.Printf("No userId provided")
+		slog.WarnContext(ctx, "websocket connection without userId")
 		return
 	}
+	ctx = logging.With(ctx, slog.String(logging.UserIDKey, userID))
+
+	ws.SetReadLimit(s.config.MaxMessageBytes)
 
-	s.register <- ws
 	s.mu.Lock()
-	s.clients[ws] = userID
+	s.clients[ws] = &client{userID: userID, ctx: ctx}
 	s.mu.Unlock()
+	activeConnections.Inc()
+	s.register <- ws
 
 	for {
 		var loc Location
 		err := ws.ReadJSON(&loc)
 		if err != nil {
-			log.Printf("Error reading location: %v", err)
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				slog.WarnContext(ctx, "error reading location", slog.Any("error", err))
+			}
 			break
 		}
 
 		loc.UserID = userID
 		loc.Timestamp = time.Now()
 
-		// Store in Redis
-		locBytes, err := json.Marshal(loc)
-		if err != nil {
-			log.Printf("Error marshaling location: %v", err)
-			continue
-		}
-
-		err = s.redis.Set(context.Background(), "location:"+userID, string(locBytes), 24*time.Hour).Err()
-		if err != nil {
-			log.Printf("Error storing location in Redis: %v", err)
+		locBytes, ok := s.storeLocation(ctx, &loc)
+		if !ok {
 			continue
 		}
 
@@ -102,79 +138,163 @@ func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.unregister <- ws
-	s.mu.Lock()
-	delete(s.clients, ws)
-	s.mu.Unlock()
+}
+
+// storeLocation encodes the update and writes it to Redis inside its own span
+func (s *Server) storeLocation(ctx context.Context, loc *Location) ([]byte, bool) {
+	ctx, span := tracer.Start(ctx, "location.update",
+		trace.WithAttributes(attribute.String("user_id", loc.UserID)),
+	)
+	defer span.End()
+
+	// Store in Redis
+	locBytes, err := json.Marshal(loc)
+	if err != nil {
+		locationUpdates.WithLabelValues("invalid").Inc()
+		span.SetStatus(codes.Error, err.Error())
+		slog.ErrorContext(ctx, "error marshaling location", slog.Any("error", err))
+		return nil, false
+	}
+
+	err = s.redis.Set(ctx, "location:"+loc.UserID, string(locBytes), s.config.LocationTTL).Err()
+	locationUpdateDuration.Observe(time.Since(loc.Timestamp).Seconds())
+	if err != nil {
+		locationUpdates.WithLabelValues("error").Inc()
+		span.SetStatus(codes.Error, err.Error())
+		slog.ErrorContext(ctx, "error storing location in redis", slog.Any("error", err))
+		return nil, false
+	}
+	locationUpdates.WithLabelValues("stored").Inc()
+
+	return locBytes, true
 }
 
 func (s *Server) run() {
 	for {
 		select {
-		case client := <-s.register:
-			s.mu.Lock()
-			log.Printf("Client connected: %s", s.clients[client])
-			s.mu.Unlock()
+		case conn := <-s.register:
+			s.mu.RLock()
+			if c, ok := s.clients[conn]; ok {
+				slog.InfoContext(c.ctx, "client connected")
+			}
+			s.mu.RUnlock()
 
-		case client := <-s.unregister:
+		case conn := <-s.unregister:
 			s.mu.Lock()
-			log.Printf("Client disconnected: %s", s.clients[client])
-			delete(s.clients, client)
+			if c, ok := s.clients[conn]; ok {
+				slog.InfoContext(c.ctx, "client disconnected")
+				delete(s.clients, conn)
+				activeConnections.Dec()
+			}
 			s.mu.Unlock()
 
 		case message := <-s.broadcast:
 			var loc Location
 			if err := json.Unmarshal(message, &loc); err != nil {
-				log.Printf("Error unmarshaling location: %v", err)
+				slog.Error("error unmarshaling location", slog.Any("error", err))
 				continue
 			}
 
-			s.mu.RLock()
-			for client, userID := range s.clients {
+			// Failed clients are removed here, so the write lock is required
+			s.mu.Lock()
+			for conn, c := range s.clients {
 				// Only send location updates to relevant users
 				// TODO: Implement proper location sharing rules
-				if userID == loc.UserID {
-					err := client.WriteMessage(websocket.TextMessage, message)
+				if c.userID == loc.UserID {
+					err := conn.WriteMessage(websocket.TextMessage, message)
 					if err != nil {
-						log.Printf("Error broadcasting to client: %v", err)
-						client.Close()
-						delete(s.clients, client)
+						slog.WarnContext(c.ctx, "error broadcasting to client", slog.Any("error", err))
+						messagesDropped.Inc()
+						activeConnections.Dec()
+						conn.Close()
+						delete(s.clients, conn)
 					}
 				}
 			}
-			s.mu.RUnlock()
+			s.mu.Unlock()
 		}
 	}
 }
 
+// newID returns a random identifier for requests and connections
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// handle registers h on the default mux with per-route latency instrumentation
+func handle(route string, h http.Handler) {
+	observer := httpRequestDuration.MustCurryWith(prometheus.Labels{"route": route})
+	http.Handle(route, promhttp.InstrumentHandlerDuration(observer, h))
+}
+
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
+
 func main() {
-	server := NewServer()
-	go server.run()
+	cfg, args, err := LoadConfig(os.Args[1:])
+	if err != nil {
+		fatal("failed to load config", err)
+	}
+
+	slog.SetDefault(logging.New("location-service", cfg.Debug))
+
+	if len(args) > 0 && args[0] == "config" {
+		if err := runConfigCommand(cfg, args[1:]); err != nil {
+			fatal("config command failed", err)
+		}
+		return
+	}
 
-	http.HandleFunc("/ws", server.handleConnections)
+	if err := cfg.Validate(); err != nil {
+		fatal("failed to load config", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "location-service", cfg.TracingOptions())
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
 
-	port := os.Getenv("LOCATION_PORT")
-	if port == "" {
-		port = "8080"
+	server, err := NewServer(cfg)
+	if err != nil {
+		fatal("failed to create server", err)
 	}
+	go server.run()
+
+	handle("/ws", http.HandlerFunc(server.handleConnections))
+	handle("/metrics", promhttp.Handler())
 
 	srv := &http.Server{
-		Addr: ":" + port,
+		Addr:              cfg.ServerAddr,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
 	}
 
-	// Graceful shutdown
+	// Graceful shutdown; main waits on done so buffered spans are flushed
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 		<-sigChan
 
-		log.Println("Shutting down server...")
-		if err := srv.Shutdown(context.Background()); err != nil {
-			log.Printf("HTTP server shutdown error: %v", err)
+		slog.Info("shutting down server")
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("http server shutdown failed", slog.Any("error", err))
+		}
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("tracing shutdown failed", slog.Any("error", err))
 		}
 	}()
 
-	log.Printf("Location service starting on port %s", port)
+	slog.Info("location service starting", slog.String("addr", cfg.ServerAddr))
 	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-		log.Fatalf("HTTP server error: %v", err)
+		fatal("http server failed", err)
 	}
+	<-done
 }
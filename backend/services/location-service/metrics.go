@@ -0,0 +1,49 @@
+package main
+
+import (
+	sharedmetrics "disco/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const metricsNamespace = "location_service"
+
+var (
+	activeConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "websocket",
+		Name:      "active_connections",
+		Help:      "Websocket clients currently connected.",
+	})
+
+	messagesDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "websocket",
+		Name:      "messages_dropped_total",
+		Help:      "Location broadcasts that could not be written; the client is disconnected.",
+	})
+
+	locationUpdates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "updates_total",
+		Help:      "Location updates received from clients, by outcome.",
+	}, []string{"result"})
+
+	locationUpdateDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "update_duration_seconds",
+		Help:      "Time to encode and store a location update in Redis.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by route; for /ws this is the lifetime of the connection.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "code", "method"})
+
+	redisMetrics = sharedmetrics.NewRedisHook(metricsNamespace, prometheus.DefaultRegisterer)
+)